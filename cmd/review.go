@@ -1,21 +1,49 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"shuttle-extensions-template/internal/pages"
+	"shuttle-extensions-template/internal/services"
 	"shuttle-extensions-template/internal/ui"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
 func ReviewCmd() *cobra.Command {
 	var (
-		squad string
+		squads         []string
+		queue          string
+		configPath     string
+		providerConfig services.ProviderConfig
 	)
 
 	cmd := &cobra.Command{
 		Use: "review",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := ui.ReviewApp(cmd.Context()); err != nil {
+			if providerConfig.Token == "" {
+				providerConfig.Token = os.Getenv(providerTokenEnv(providerConfig.Name))
+			}
+
+			base, err := services.NewPullRequestProvider(providerConfig)
+			if err != nil {
+				return err
+			}
+
+			queues, err := reviewQueues(cmd.Context(), base, squads, configPath)
+			if err != nil {
+				return err
+			}
+			if queue != "" && !slices.ContainsFunc(queues, func(q pages.Queue) bool { return q.Name == queue }) {
+				return fmt.Errorf("unknown queue %s, it is not defined in %s", queue, configPath)
+			}
+
+			if err := ui.ReviewApp(cmd.Context(), queues, queue); err != nil {
 				log.Fatal(err)
 				return err
 			}
@@ -24,7 +52,62 @@ func ReviewCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&squad, "squad", "", "which squad to filter for, @lunarway/squad-aura")
+	cmd.Flags().StringSliceVar(&squads, "squad", nil, "which squads to filter for, @lunarway/squad-aura, can be repeated")
+	cmd.Flags().StringVar(&queue, "queue", "", "which named queue from the config file to start on, e.g. deps")
+	cmd.Flags().StringVar(&configPath, "config", defaultConfigPath(), "config file defining the named queues")
+	cmd.Flags().StringVar(&providerConfig.Name, "provider", services.ProviderGitHub, fmt.Sprintf("where to fetch pull requests from, one of %s", strings.Join(services.Providers, ", ")))
+	cmd.Flags().StringVar(&providerConfig.URL, "provider-url", "", "api url of the provider, e.g. https://<host>/api/v3 for github enterprise or https://<host> for gitlab and gitea")
+	cmd.Flags().StringVar(&providerConfig.Token, "provider-token", "", "api token for the provider, defaults to $<PROVIDER>_TOKEN, e.g. $GITHUB_TOKEN")
 
 	return cmd
 }
+
+// reviewQueues returns the review requests of the squads followed by the
+// queues defined in the config file, each ranked most urgent first.
+func reviewQueues(ctx context.Context, base services.PullRequestProvider, squads []string, configPath string) ([]pages.Queue, error) {
+	config, err := services.LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := base
+	name := "review requests"
+	reviewers := squads
+	if len(squads) > 0 {
+		provider = services.NewSquadPullRequestProvider(base, squads)
+		name = strings.Join(squads, ", ")
+	} else if reader, ok := base.(services.ReviewersReader); ok {
+		// Without squads the review requests are the ones made to us or our
+		// teams, those are the explicit requests.
+		reviewers, err = reader.GetReviewers(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ranker := services.NewWeightedRanker(config.Ranking, reviewers)
+	queues := []pages.Queue{{Name: name, Provider: services.NewRankedPullRequestProvider(provider, ranker)}}
+
+	for _, q := range config.Queues {
+		provider, err := q.Provider(base)
+		if err != nil {
+			return nil, err
+		}
+		ranker := services.NewWeightedRanker(config.Ranking, append(slices.Clone(reviewers), q.Squads...))
+		queues = append(queues, pages.Queue{Name: q.Name, Provider: services.NewRankedPullRequestProvider(provider, ranker)})
+	}
+
+	return queues, nil
+}
+
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "dr.yaml"
+	}
+
+	return filepath.Join(dir, "dr", "config.yaml")
+}
+
+func providerTokenEnv(provider string) string {
+	return strings.ToUpper(provider) + "_TOKEN"
+}
@@ -1,7 +1,9 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"shuttle-extensions-template/internal/notifications"
 	"shuttle-extensions-template/internal/pages"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -20,31 +22,53 @@ func WithPage(page string) AppOptions {
 	}
 }
 
+// WithQueue starts out on the named queue rather than the first.
+func WithQueue(name string) AppOptions {
+	return func(a *App) {
+		a.queue = name
+	}
+}
+
 type App struct {
-	pages       map[string]Page
-	currentPage string
+	pages         map[string]Page
+	currentPage   string
+	queue         string
+	notifications notifications.Model
+	// history holds the pages to go back to, the most recent last.
+	history []string
 
 	width, height int
 }
 
-func NewApp(opts ...AppOptions) *App {
+// NewApp reviews the pull requests of the queues, there must be at least one.
+func NewApp(ctx context.Context, queues []pages.Queue, opts ...AppOptions) *App {
 	app := &App{
-		pages: map[string]Page{
-			pages.PullRequestTablePage:  pages.NewPullRequestTable(),
-			pages.PullRequestReviewPage: pages.NewPullRequestReview(),
-		},
-		currentPage: pages.PullRequestTablePage,
+		currentPage:   pages.PullRequestTablePage,
+		notifications: notifications.New(),
 	}
 
 	for _, opt := range opts {
 		opt(app)
 	}
 
+	queue := 0
+	for i := range queues {
+		if queues[i].Name == app.queue {
+			queue = i
+		}
+	}
+
+	app.pages = map[string]Page{
+		pages.PullRequestTablePage:  pages.NewPullRequestTable(ctx, queues, queue),
+		pages.PullRequestReviewPage: pages.NewPullRequestReview(ctx, queues[queue].Provider),
+		pages.ErrorPage:             pages.NewError(),
+	}
+
 	return app
 }
 
 func (a *App) Init() tea.Cmd {
-	return nil
+	return a.pages[a.currentPage].Init()
 }
 
 func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -53,24 +77,49 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		k := msg.String()
-		if k == "q" || k == "esc" || k == "ctrl+c" {
+		if k == "ctrl+c" {
+			return a, tea.Quit
+		}
+		if k == "esc" && len(a.history) > 0 && !a.capturingInput() {
+			return a, pages.NewPopPage()
+		}
+		if (k == "q" || k == "esc") && !a.capturingInput() {
 			return a, tea.Quit
 		}
 	case pages.ChangePage:
-		page := msg.Page()
-		_, ok := a.pages[page]
+		cmd, ok := a.navigate(msg)
 		if !ok {
-			panic(fmt.Errorf("error: page was not found: %s", page))
+			return a, pages.ShowError("Page not found", fmt.Errorf("page was not found: %s", msg.Page()), nil)
 		}
-		a.currentPage = page
-
-		cmds = append(cmds, a.pages[page].Init())
-		a.pages[page].SetSize(a.width, a.height)
+		cmds = append(cmds, cmd)
+	case pages.ErrorMsg:
+		if a.currentPage != pages.ErrorPage {
+			a.history = append(a.history, a.currentPage)
+		}
+		a.currentPage = pages.ErrorPage
+		a.pages[pages.ErrorPage].SetSize(a.width, a.height)
 	case tea.WindowSizeMsg:
 		h, v := docStyle.GetFrameSize()
 		a.SetSize(msg.Width-h, msg.Height-v)
+
+		// Pages in the history are kept in step so they show up laid out
+		// when returning to them.
+		cmds = append(cmds, a.broadcast(msg))
+	case pages.QueueSelectedMsg:
+		cmds = append(cmds, a.broadcast(msg))
+	case pages.PageMsg:
+		if page, ok := a.pages[msg.TargetPage()]; ok && msg.TargetPage() != a.currentPage {
+			newPage, newCmd := page.Update(msg)
+			a.pages[msg.TargetPage()] = newPage.(Page)
+
+			return a, newCmd
+		}
 	}
 
+	var cmd tea.Cmd
+	a.notifications, cmd = a.notifications.Update(msg)
+	cmds = append(cmds, cmd)
+
 	if a.pages[a.currentPage] != nil {
 		newPage, newCmd := a.pages[a.currentPage].Update(msg)
 		a.pages[a.currentPage] = newPage.(Page)
@@ -81,7 +130,58 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (a *App) View() string {
-	return a.pages[a.currentPage].View()
+	return a.notifications.Overlay(a.pages[a.currentPage].View(), a.width, a.height)
+}
+
+// broadcast updates the pages other than the current one with the message,
+// the current page is updated along with every other message.
+func (a *App) broadcast(msg tea.Msg) tea.Cmd {
+	cmds := make([]tea.Cmd, 0, len(a.pages))
+	for name, page := range a.pages {
+		if name != a.currentPage {
+			newPage, newCmd := page.Update(msg)
+			a.pages[name] = newPage.(Page)
+			cmds = append(cmds, newCmd)
+		}
+	}
+
+	return tea.Batch(cmds...)
+}
+
+// navigate changes the current page. Pages keep their state while out of view,
+// so going back only shows the previous page again while other changes
+// initialize the page shown.
+func (a *App) navigate(msg pages.ChangePage) (tea.Cmd, bool) {
+	if msg.Navigation() == pages.NavigationPop {
+		if len(a.history) == 0 {
+			return nil, true
+		}
+
+		a.currentPage = a.history[len(a.history)-1]
+		a.history = a.history[:len(a.history)-1]
+		a.pages[a.currentPage].SetSize(a.width, a.height)
+
+		return nil, true
+	}
+
+	page := msg.Page()
+	if _, ok := a.pages[page]; !ok {
+		return nil, false
+	}
+
+	if msg.Navigation() == pages.NavigationPush {
+		a.history = append(a.history, a.currentPage)
+	}
+	a.currentPage = page
+	a.pages[page].SetSize(a.width, a.height)
+
+	return a.pages[page].Init(), true
+}
+
+func (a *App) capturingInput() bool {
+	capturer, ok := a.pages[a.currentPage].(InputCapturer)
+
+	return ok && capturer.CapturingInput()
 }
 
 func (a *App) SetSize(width, height int) {
@@ -95,3 +195,9 @@ type Page interface {
 	tea.Model
 	SetSize(width, height int)
 }
+
+// InputCapturer is implemented by pages taking text input, the global key
+// bindings are left to the page while it is capturing.
+type InputCapturer interface {
+	CapturingInput() bool
+}
@@ -0,0 +1,443 @@
+// Package diff parses unified diffs, as produced by git diff, into files,
+// hunks and lines.
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type LineKind int
+
+const (
+	LineContext LineKind = iota
+	LineAdded
+	LineRemoved
+)
+
+func (k LineKind) Prefix() string {
+	switch k {
+	case LineAdded:
+		return "+"
+	case LineRemoved:
+		return "-"
+	default:
+		return " "
+	}
+}
+
+type Line struct {
+	Kind LineKind
+	// Content is the line without its +, - or space prefix.
+	Content string
+	// OldNumber and NewNumber are the line numbers in the old and new file,
+	// they are 0 when the line doesn't exist on that side.
+	OldNumber int
+	NewNumber int
+	// NoNewline marks the last line of a file without a trailing newline.
+	NoNewline bool
+}
+
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	// Section is the text after the hunk range, usually the enclosing
+	// function.
+	Section string
+	Lines   []Line
+}
+
+// Header renders the hunk range line, such as @@ -1,3 +1,4 @@ func main().
+func (h Hunk) Header() string {
+	header := fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+	if h.Section != "" {
+		header += " " + h.Section
+	}
+
+	return header
+}
+
+type FileStatus string
+
+const (
+	StatusAdded    FileStatus = "added"
+	StatusDeleted  FileStatus = "deleted"
+	StatusModified FileStatus = "modified"
+	StatusRenamed  FileStatus = "renamed"
+	StatusCopied   FileStatus = "copied"
+)
+
+type File struct {
+	OldPath string
+	NewPath string
+	Status  FileStatus
+	// OldMode and NewMode are the git file modes, such as 100644, they are
+	// only set when known from the headers.
+	OldMode string
+	NewMode string
+	// Similarity is the similarity index in percent of renames and copies.
+	Similarity int
+	Binary     bool
+	// Headers are the extended git header lines between diff --git and the
+	// first hunk.
+	Headers []string
+	Hunks   []Hunk
+}
+
+// Path is the path of the file after the change, or before it for deleted
+// files.
+func (f *File) Path() string {
+	if f.Status == StatusDeleted {
+		return f.OldPath
+	}
+
+	return f.NewPath
+}
+
+// ModeChanged reports whether the change, for example, made the file
+// executable.
+func (f *File) ModeChanged() bool {
+	return f.OldMode != "" && f.NewMode != "" && f.OldMode != f.NewMode
+}
+
+func (f *File) Additions() int {
+	return f.count(LineAdded)
+}
+
+func (f *File) Deletions() int {
+	return f.count(LineRemoved)
+}
+
+func (f *File) count(kind LineKind) int {
+	count := 0
+	for _, hunk := range f.Hunks {
+		for _, line := range hunk.Lines {
+			if line.Kind == kind {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+var hunkHeaderRegexp = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@ ?(.*)$`)
+
+type parser struct {
+	lines []string
+	pos   int
+	files []*File
+}
+
+// Parse parses a unified diff. Both git style diffs with diff --git headers
+// and plain diffs starting at the --- line are supported.
+func Parse(input string) ([]*File, error) {
+	input = strings.TrimSuffix(input, "\n")
+	if input == "" {
+		return []*File{}, nil
+	}
+
+	p := &parser{
+		lines: strings.Split(input, "\n"),
+		files: make([]*File, 0),
+	}
+
+	for p.pos < len(p.lines) {
+		line := strings.TrimSuffix(p.lines[p.pos], "\r")
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			p.files = append(p.files, parseGitHeader(line))
+			p.pos++
+		case strings.HasPrefix(line, "--- ") && p.peek(1, "+++ "):
+			file := p.current()
+			if file == nil || len(file.Hunks) > 0 {
+				file = &File{Status: StatusModified}
+				p.files = append(p.files, file)
+			}
+			p.parsePaths(file)
+		case strings.HasPrefix(line, "@@"):
+			file := p.current()
+			if file == nil {
+				return nil, fmt.Errorf("line %d: hunk outside of a file", p.pos+1)
+			}
+			if err := p.parseHunk(file); err != nil {
+				return nil, err
+			}
+		default:
+			if file := p.current(); file != nil && len(file.Hunks) == 0 {
+				parseExtendedHeader(file, line)
+			}
+			p.pos++
+		}
+	}
+
+	return p.files, nil
+}
+
+func (p *parser) current() *File {
+	if len(p.files) == 0 {
+		return nil
+	}
+
+	return p.files[len(p.files)-1]
+}
+
+func (p *parser) peek(offset int, prefix string) bool {
+	if p.pos+offset >= len(p.lines) {
+		return false
+	}
+
+	return strings.HasPrefix(p.lines[p.pos+offset], prefix)
+}
+
+func (p *parser) parsePaths(file *File) {
+	oldPath := parsePath(strings.TrimPrefix(p.lines[p.pos], "--- "))
+	newPath := parsePath(strings.TrimPrefix(p.lines[p.pos+1], "+++ "))
+	p.pos += 2
+
+	switch {
+	case oldPath == "/dev/null":
+		file.Status = StatusAdded
+		file.NewPath = newPath
+	case newPath == "/dev/null":
+		file.Status = StatusDeleted
+		file.OldPath = oldPath
+	default:
+		file.OldPath = oldPath
+		file.NewPath = newPath
+	}
+	if file.OldPath == "" {
+		file.OldPath = file.NewPath
+	}
+	if file.NewPath == "" {
+		file.NewPath = file.OldPath
+	}
+}
+
+func (p *parser) parseHunk(file *File) error {
+	header := strings.TrimSuffix(p.lines[p.pos], "\r")
+	match := hunkHeaderRegexp.FindStringSubmatch(header)
+	if match == nil {
+		return fmt.Errorf("line %d: invalid hunk header: %s", p.pos+1, header)
+	}
+	p.pos++
+
+	hunk := Hunk{
+		OldStart: atoi(match[1], 0),
+		OldLines: atoi(match[2], 1),
+		NewStart: atoi(match[3], 0),
+		NewLines: atoi(match[4], 1),
+		Section:  match[5],
+	}
+	hunk.Lines = make([]Line, 0, max(hunk.OldLines, hunk.NewLines))
+
+	oldNumber, newNumber := hunk.OldStart, hunk.NewStart
+	oldRemaining, newRemaining := hunk.OldLines, hunk.NewLines
+
+	for p.pos < len(p.lines) {
+		line := strings.TrimSuffix(p.lines[p.pos], "\r")
+
+		if strings.HasPrefix(line, `\`) {
+			// \ No newline at end of file
+			if len(hunk.Lines) > 0 {
+				hunk.Lines[len(hunk.Lines)-1].NoNewline = true
+			}
+			p.pos++
+			continue
+		}
+		if oldRemaining <= 0 && newRemaining <= 0 {
+			break
+		}
+
+		var kind LineKind
+		switch {
+		case strings.HasPrefix(line, "+"):
+			kind = LineAdded
+		case strings.HasPrefix(line, "-"):
+			kind = LineRemoved
+		case strings.HasPrefix(line, " "), line == "":
+			// Some tools strip the trailing space of empty context lines.
+			kind = LineContext
+		default:
+			return fmt.Errorf("line %d: unexpected line in hunk: %s", p.pos+1, line)
+		}
+
+		content := ""
+		if line != "" {
+			content = line[1:]
+		}
+
+		diffLine := Line{Kind: kind, Content: content}
+		switch kind {
+		case LineAdded:
+			diffLine.NewNumber = newNumber
+			newNumber++
+			newRemaining--
+		case LineRemoved:
+			diffLine.OldNumber = oldNumber
+			oldNumber++
+			oldRemaining--
+		default:
+			diffLine.OldNumber = oldNumber
+			diffLine.NewNumber = newNumber
+			oldNumber++
+			newNumber++
+			oldRemaining--
+			newRemaining--
+		}
+
+		hunk.Lines = append(hunk.Lines, diffLine)
+		p.pos++
+	}
+
+	if oldRemaining > 0 || newRemaining > 0 {
+		return fmt.Errorf("line %d: hunk %s ended early", p.pos, header)
+	}
+
+	file.Hunks = append(file.Hunks, hunk)
+
+	return nil
+}
+
+func parseGitHeader(line string) *File {
+	file := &File{Status: StatusModified}
+	rest := strings.TrimPrefix(line, "diff --git ")
+
+	if strings.HasPrefix(rest, `"`) {
+		oldPath, remaining, err := unquote(rest)
+		if err == nil {
+			file.OldPath = stripPrefix(oldPath)
+			file.NewPath = parsePath(strings.TrimSpace(remaining))
+			return file
+		}
+	}
+
+	// Without a rename both paths are equal, which disambiguates paths
+	// containing spaces.
+	if n := (len(rest) - 1) / 2; len(rest)%2 == 1 && rest[n] == ' ' && stripPrefix(rest[:n]) == stripPrefix(rest[n+1:]) {
+		file.OldPath = stripPrefix(rest[:n])
+		file.NewPath = file.OldPath
+		return file
+	}
+
+	if i := strings.LastIndex(rest, " b/"); i >= 0 {
+		file.OldPath = stripPrefix(rest[:i])
+		file.NewPath = parsePath(rest[i+1:])
+	}
+
+	return file
+}
+
+func parseExtendedHeader(file *File, line string) {
+	file.Headers = append(file.Headers, line)
+
+	switch {
+	case strings.HasPrefix(line, "new file mode "):
+		file.Status = StatusAdded
+		file.NewMode = strings.TrimPrefix(line, "new file mode ")
+	case strings.HasPrefix(line, "deleted file mode "):
+		file.Status = StatusDeleted
+		file.OldMode = strings.TrimPrefix(line, "deleted file mode ")
+	case strings.HasPrefix(line, "old mode "):
+		file.OldMode = strings.TrimPrefix(line, "old mode ")
+	case strings.HasPrefix(line, "new mode "):
+		file.NewMode = strings.TrimPrefix(line, "new mode ")
+	case strings.HasPrefix(line, "rename from "):
+		file.Status = StatusRenamed
+		file.OldPath = parseRawPath(strings.TrimPrefix(line, "rename from "))
+	case strings.HasPrefix(line, "rename to "):
+		file.Status = StatusRenamed
+		file.NewPath = parseRawPath(strings.TrimPrefix(line, "rename to "))
+	case strings.HasPrefix(line, "copy from "):
+		file.Status = StatusCopied
+		file.OldPath = parseRawPath(strings.TrimPrefix(line, "copy from "))
+	case strings.HasPrefix(line, "copy to "):
+		file.Status = StatusCopied
+		file.NewPath = parseRawPath(strings.TrimPrefix(line, "copy to "))
+	case strings.HasPrefix(line, "similarity index "):
+		file.Similarity = atoi(strings.TrimSuffix(strings.TrimPrefix(line, "similarity index "), "%"), 0)
+	case strings.HasPrefix(line, "index "):
+		// index 4532416..780b81e 100644
+		fields := strings.Fields(line)
+		if len(fields) == 3 && file.OldMode == "" && file.NewMode == "" {
+			file.OldMode = fields[2]
+			file.NewMode = fields[2]
+		}
+	case strings.HasPrefix(line, "Binary files "), line == "GIT binary patch":
+		file.Binary = true
+	}
+}
+
+// parsePath parses a path from a --- or +++ line, removing the a/ or b/
+// prefix, quotes and any trailing timestamp.
+func parsePath(path string) string {
+	if strings.HasPrefix(path, `"`) {
+		if unquoted, _, err := unquote(path); err == nil {
+			return stripPrefix(unquoted)
+		}
+	}
+
+	// Plain diffs may append a tab and a timestamp.
+	path, _, _ = strings.Cut(path, "\t")
+	if path == "/dev/null" {
+		return path
+	}
+
+	return stripPrefix(path)
+}
+
+func parseRawPath(path string) string {
+	if strings.HasPrefix(path, `"`) {
+		if unquoted, _, err := unquote(path); err == nil {
+			return unquoted
+		}
+	}
+
+	return path
+}
+
+func stripPrefix(path string) string {
+	if strings.HasPrefix(path, "a/") || strings.HasPrefix(path, "b/") {
+		return path[2:]
+	}
+
+	return path
+}
+
+// unquote reads a leading C style quoted string, as git uses for paths with
+// special characters, returning it and the remaining input.
+func unquote(s string) (string, string, error) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			unquoted, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", "", err
+			}
+
+			return unquoted, s[i+1:], nil
+		}
+	}
+
+	return "", "", fmt.Errorf("unterminated quoted path: %s", s)
+}
+
+func atoi(s string, fallback int) int {
+	if s == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fallback
+	}
+
+	return n
+}
@@ -0,0 +1,382 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []*File
+	}{
+		{
+			name:  "empty",
+			input: "",
+			want:  []*File{},
+		},
+		{
+			name: "modified",
+			input: `diff --git a/main.go b/main.go
+index 4532416..780b81e 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,3 @@ package main
+ import "fmt"
+-var x = 1
++var x = 2
+
+`,
+			want: []*File{{
+				OldPath: "main.go",
+				NewPath: "main.go",
+				Status:  StatusModified,
+				OldMode: "100644",
+				NewMode: "100644",
+				Headers: []string{"index 4532416..780b81e 100644"},
+				Hunks: []Hunk{{
+					OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3,
+					Section: "package main",
+					Lines: []Line{
+						{Kind: LineContext, Content: `import "fmt"`, OldNumber: 1, NewNumber: 1},
+						{Kind: LineRemoved, Content: "var x = 1", OldNumber: 2},
+						{Kind: LineAdded, Content: "var x = 2", NewNumber: 2},
+						{Kind: LineContext, Content: "", OldNumber: 3, NewNumber: 3},
+					},
+				}},
+			}},
+		},
+		{
+			name: "rename with changes",
+			input: `diff --git a/old.go b/new.go
+similarity index 90%
+rename from old.go
+rename to new.go
+index 1111111..2222222 100644
+--- a/old.go
++++ b/new.go
+@@ -1 +1 @@
+-a
++b
+`,
+			want: []*File{{
+				OldPath:    "old.go",
+				NewPath:    "new.go",
+				Status:     StatusRenamed,
+				OldMode:    "100644",
+				NewMode:    "100644",
+				Similarity: 90,
+				Headers: []string{
+					"similarity index 90%",
+					"rename from old.go",
+					"rename to new.go",
+					"index 1111111..2222222 100644",
+				},
+				Hunks: []Hunk{{
+					OldStart: 1, OldLines: 1, NewStart: 1, NewLines: 1,
+					Lines: []Line{
+						{Kind: LineRemoved, Content: "a", OldNumber: 1},
+						{Kind: LineAdded, Content: "b", NewNumber: 1},
+					},
+				}},
+			}},
+		},
+		{
+			name: "pure rename",
+			input: `diff --git a/a.txt b/docs/a.txt
+similarity index 100%
+rename from a.txt
+rename to docs/a.txt
+`,
+			want: []*File{{
+				OldPath:    "a.txt",
+				NewPath:    "docs/a.txt",
+				Status:     StatusRenamed,
+				Similarity: 100,
+				Headers:    []string{"similarity index 100%", "rename from a.txt", "rename to docs/a.txt"},
+			}},
+		},
+		{
+			name: "copy",
+			input: `diff --git a/a.txt b/b.txt
+similarity index 100%
+copy from a.txt
+copy to b.txt
+`,
+			want: []*File{{
+				OldPath:    "a.txt",
+				NewPath:    "b.txt",
+				Status:     StatusCopied,
+				Similarity: 100,
+				Headers:    []string{"similarity index 100%", "copy from a.txt", "copy to b.txt"},
+			}},
+		},
+		{
+			name: "binary",
+			input: `diff --git a/logo.png b/logo.png
+index 1111111..2222222 100644
+Binary files a/logo.png and b/logo.png differ
+`,
+			want: []*File{{
+				OldPath: "logo.png",
+				NewPath: "logo.png",
+				Status:  StatusModified,
+				OldMode: "100644",
+				NewMode: "100644",
+				Binary:  true,
+				Headers: []string{"index 1111111..2222222 100644", "Binary files a/logo.png and b/logo.png differ"},
+			}},
+		},
+		{
+			name: "added binary",
+			input: `diff --git a/logo.png b/logo.png
+new file mode 100644
+index 0000000..2222222
+GIT binary patch
+literal 4
+LcmZQzWMT#Y01f~L
+`,
+			want: []*File{{
+				OldPath: "logo.png",
+				NewPath: "logo.png",
+				Status:  StatusAdded,
+				NewMode: "100644",
+				Binary:  true,
+				Headers: []string{
+					"new file mode 100644",
+					"index 0000000..2222222",
+					"GIT binary patch",
+					"literal 4",
+					"LcmZQzWMT#Y01f~L",
+				},
+			}},
+		},
+		{
+			name: "mode only",
+			input: `diff --git a/run.sh b/run.sh
+old mode 100644
+new mode 100755
+`,
+			want: []*File{{
+				OldPath: "run.sh",
+				NewPath: "run.sh",
+				Status:  StatusModified,
+				OldMode: "100644",
+				NewMode: "100755",
+				Headers: []string{"old mode 100644", "new mode 100755"},
+			}},
+		},
+		{
+			name: "added and deleted",
+			input: `diff --git a/new.txt b/new.txt
+new file mode 100644
+index 0000000..1111111
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1 @@
++hello
+diff --git a/old.txt b/old.txt
+deleted file mode 100644
+index 1111111..0000000
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+`,
+			want: []*File{
+				{
+					OldPath: "new.txt",
+					NewPath: "new.txt",
+					Status:  StatusAdded,
+					NewMode: "100644",
+					Headers: []string{"new file mode 100644", "index 0000000..1111111"},
+					Hunks: []Hunk{{
+						OldStart: 0, OldLines: 0, NewStart: 1, NewLines: 1,
+						Lines: []Line{{Kind: LineAdded, Content: "hello", NewNumber: 1}},
+					}},
+				},
+				{
+					OldPath: "old.txt",
+					NewPath: "old.txt",
+					Status:  StatusDeleted,
+					OldMode: "100644",
+					Headers: []string{"deleted file mode 100644", "index 1111111..0000000"},
+					Hunks: []Hunk{{
+						OldStart: 1, OldLines: 1, NewStart: 0, NewLines: 0,
+						Lines: []Line{{Kind: LineRemoved, Content: "bye", OldNumber: 1}},
+					}},
+				},
+			},
+		},
+		{
+			name: "quoted paths",
+			input: `diff --git "a/tab\there.txt" "b/caf\303\251.txt"
+similarity index 80%
+rename from "tab\there.txt"
+rename to "caf\303\251.txt"
+--- "a/tab\there.txt"
++++ "b/caf\303\251.txt"
+@@ -1 +1 @@
+-x
++y
+`,
+			want: []*File{{
+				OldPath:    "tab\there.txt",
+				NewPath:    "café.txt",
+				Status:     StatusRenamed,
+				Similarity: 80,
+				Headers: []string{
+					"similarity index 80%",
+					`rename from "tab\there.txt"`,
+					`rename to "caf\303\251.txt"`,
+				},
+				Hunks: []Hunk{{
+					OldStart: 1, OldLines: 1, NewStart: 1, NewLines: 1,
+					Lines: []Line{
+						{Kind: LineRemoved, Content: "x", OldNumber: 1},
+						{Kind: LineAdded, Content: "y", NewNumber: 1},
+					},
+				}},
+			}},
+		},
+		{
+			name: "path with spaces",
+			input: `diff --git a/my file.txt b/my file.txt
+old mode 100644
+new mode 100755
+`,
+			want: []*File{{
+				OldPath: "my file.txt",
+				NewPath: "my file.txt",
+				Status:  StatusModified,
+				OldMode: "100644",
+				NewMode: "100755",
+				Headers: []string{"old mode 100644", "new mode 100755"},
+			}},
+		},
+		{
+			name: "no newline at end of file",
+			input: `diff --git a/a.txt b/a.txt
+--- a/a.txt
++++ b/a.txt
+@@ -1 +1 @@
+-old
+\ No newline at end of file
++new
+\ No newline at end of file
+`,
+			want: []*File{{
+				OldPath: "a.txt",
+				NewPath: "a.txt",
+				Status:  StatusModified,
+				Hunks: []Hunk{{
+					OldStart: 1, OldLines: 1, NewStart: 1, NewLines: 1,
+					Lines: []Line{
+						{Kind: LineRemoved, Content: "old", OldNumber: 1, NoNewline: true},
+						{Kind: LineAdded, Content: "new", NewNumber: 1, NoNewline: true},
+					},
+				}},
+			}},
+		},
+		{
+			name:  "plain diff",
+			input: "--- a.txt\t2024-01-01 10:00:00\n+++ a.txt\t2024-01-02 10:00:00\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n",
+			want: []*File{{
+				OldPath: "a.txt",
+				NewPath: "a.txt",
+				Status:  StatusModified,
+				Hunks: []Hunk{{
+					OldStart: 1, OldLines: 2, NewStart: 1, NewLines: 2,
+					Lines: []Line{
+						{Kind: LineContext, Content: "a", OldNumber: 1, NewNumber: 1},
+						{Kind: LineRemoved, Content: "b", OldNumber: 2},
+						{Kind: LineAdded, Content: "c", NewNumber: 2},
+					},
+				}},
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Parse(test.input)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if len(got) != len(test.want) {
+				t.Fatalf("Parse() returned %d files, want %d", len(got), len(test.want))
+			}
+			for i := range got {
+				if !reflect.DeepEqual(got[i], test.want[i]) {
+					t.Errorf("file %d = %+v, want %+v", i, got[i], test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "invalid hunk header",
+			input: "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n@@ -x +1 @@\n-a\n+b\n",
+			want:  "line 4: invalid hunk header: @@ -x +1 @@",
+		},
+		{
+			name:  "truncated hunk",
+			input: "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n@@ -1,3 +1,3 @@\n a\n-b\n",
+			want:  "ended early",
+		},
+		{
+			name:  "hunk cut by the next file",
+			input: "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n@@ -1,2 +1,2 @@\n a\ndiff --git a/b.txt b/b.txt\n",
+			want:  "unexpected line in hunk: diff --git a/b.txt b/b.txt",
+		},
+		{
+			name:  "unexpected line in hunk",
+			input: "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n@@ -1,2 +1,2 @@\n a\n*b\n",
+			want:  "line 6: unexpected line in hunk: *b",
+		},
+		{
+			name:  "hunk outside of a file",
+			input: "@@ -1 +1 @@\n-a\n+b\n",
+			want:  "line 1: hunk outside of a file",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(test.input)
+			if err == nil {
+				t.Fatal("Parse() error = nil")
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Errorf("Parse() error = %q, want it to contain %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestFileCounts(t *testing.T) {
+	files, err := Parse("diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n@@ -1,2 +1,3 @@\n a\n-b\n+c\n+d\n")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got := files[0].Additions(); got != 2 {
+		t.Errorf("Additions() = %d, want 2", got)
+	}
+	if got := files[0].Deletions(); got != 1 {
+		t.Errorf("Deletions() = %d, want 1", got)
+	}
+	if files[0].ModeChanged() {
+		t.Error("ModeChanged() = true, want false")
+	}
+}
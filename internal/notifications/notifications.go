@@ -0,0 +1,159 @@
+// Package notifications shows short lived toasts stacked in the corner of the
+// screen. Pages emit them with Notify and the app renders them on top.
+package notifications
+
+import (
+	"fmt"
+	"shuttle-extensions-template/internal/utility"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeveritySuccess
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) icon() string {
+	switch s {
+	case SeveritySuccess:
+		return "✓"
+	case SeverityWarning:
+		return "!"
+	case SeverityError:
+		return "✗"
+	default:
+		return "i"
+	}
+}
+
+func (s Severity) color() lipgloss.Color {
+	switch s {
+	case SeveritySuccess:
+		return lipgloss.Color("#50FA7B")
+	case SeverityWarning:
+		return lipgloss.Color("#F1FA8C")
+	case SeverityError:
+		return lipgloss.Color("#FF5555")
+	default:
+		return lipgloss.Color("#8BE9FD")
+	}
+}
+
+// duration is how long a toast is shown, errors stay long enough to be read.
+func (s Severity) duration() time.Duration {
+	if s == SeverityError {
+		return 10 * time.Second
+	}
+
+	return 4 * time.Second
+}
+
+// NotificationMsg queues a toast.
+type NotificationMsg struct {
+	Severity Severity
+	Text     string
+}
+
+// Notify returns a command emitting a notification, the text is formatted
+// like fmt.Sprintf.
+func Notify(severity Severity, format string, args ...any) tea.Cmd {
+	text := fmt.Sprintf(format, args...)
+
+	return func() tea.Msg {
+		return NotificationMsg{Severity: severity, Text: text}
+	}
+}
+
+type dismissMsg struct {
+	id int
+}
+
+type toast struct {
+	id       int
+	severity Severity
+	text     string
+}
+
+const (
+	toastWidth = 40
+	// maxToasts is the number of toasts shown at once, the oldest are
+	// dismissed early to make room.
+	maxToasts = 4
+)
+
+// Model holds the toasts currently shown.
+type Model struct {
+	toasts []toast
+	nextID int
+}
+
+func New() Model {
+	return Model{}
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case NotificationMsg:
+		id := m.nextID
+		m.nextID++
+
+		m.toasts = append(m.toasts, toast{id: id, severity: msg.Severity, text: msg.Text})
+		if len(m.toasts) > maxToasts {
+			m.toasts = m.toasts[len(m.toasts)-maxToasts:]
+		}
+
+		return m, tea.Tick(msg.Severity.duration(), func(time.Time) tea.Msg {
+			return dismissMsg{id: id}
+		})
+	case dismissMsg:
+		toasts := make([]toast, 0, len(m.toasts))
+		for _, toast := range m.toasts {
+			if toast.id != msg.id {
+				toasts = append(toasts, toast)
+			}
+		}
+		m.toasts = toasts
+	}
+
+	return m, nil
+}
+
+var toastStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	Padding(0, 1).
+	Width(toastWidth)
+
+// View stacks the toasts with the newest at the bottom.
+func (m Model) View() string {
+	rendered := make([]string, 0, len(m.toasts))
+	for _, toast := range m.toasts {
+		icon := lipgloss.NewStyle().Foreground(toast.severity.color()).Render(toast.severity.icon())
+		rendered = append(rendered, toastStyle.Copy().
+			BorderForeground(toast.severity.color()).
+			Render(icon+" "+toast.text))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Right, rendered...)
+}
+
+// Overlay places the toasts in the bottom right corner of the content.
+func (m Model) Overlay(content string, width, height int) string {
+	if len(m.toasts) == 0 {
+		return content
+	}
+
+	toasts := m.View()
+
+	return utility.PlaceOverlay(
+		max(width-lipgloss.Width(toasts), 0), max(height-lipgloss.Height(toasts), 0),
+		toasts, content,
+		false,
+	)
+}
@@ -2,18 +2,50 @@ package pages
 
 import tea "github.com/charmbracelet/bubbletea"
 
+// Navigation is how a page change affects the history of visited pages.
+type Navigation int
+
+const (
+	// NavigationReplace shows the page in place of the current one.
+	NavigationReplace Navigation = iota
+	// NavigationPush shows the page, going back returns to the current one.
+	NavigationPush
+	// NavigationPop returns to the previous page.
+	NavigationPop
+)
+
 type ChangePage interface {
 	Page() string
+	Navigation() Navigation
 }
 
 type changePage struct {
-	page string
+	page       string
+	navigation Navigation
 }
 
 func NewChangePage(page string) tea.Cmd {
 	return func() tea.Msg {
 		return &changePage{
-			page: page,
+			page:       page,
+			navigation: NavigationReplace,
+		}
+	}
+}
+
+func NewPushPage(page string) tea.Cmd {
+	return func() tea.Msg {
+		return &changePage{
+			page:       page,
+			navigation: NavigationPush,
+		}
+	}
+}
+
+func NewPopPage() tea.Cmd {
+	return func() tea.Msg {
+		return &changePage{
+			navigation: NavigationPop,
 		}
 	}
 }
@@ -22,5 +54,15 @@ func (changePage *changePage) Page() string {
 	return changePage.page
 }
 
+func (changePage *changePage) Navigation() Navigation {
+	return changePage.navigation
+}
+
 var _ tea.Msg = &changePage{}
 var _ ChangePage = &changePage{}
+
+// PageMsg is implemented by the results of work a page started in the
+// background, they are delivered to the page even when it is out of view.
+type PageMsg interface {
+	TargetPage() string
+}
@@ -0,0 +1,190 @@
+package pages
+
+import (
+	"errors"
+	"fmt"
+	"shuttle-extensions-template/internal/services"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type confirmModalKeyMap struct {
+	Confirm    key.Binding
+	NextMethod key.Binding
+	Cancel     key.Binding
+}
+
+func newConfirmModalKeyMap() confirmModalKeyMap {
+	return confirmModalKeyMap{
+		Confirm: key.NewBinding(
+			key.WithKeys("enter", "y"),
+			key.WithHelp("enter", "confirm"),
+		),
+		NextMethod: key.NewBinding(
+			key.WithKeys("tab", "right", "l"),
+			key.WithHelp("tab", "merge method"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc", "n"),
+			key.WithHelp("esc", "cancel"),
+		),
+	}
+}
+
+var (
+	errMergeConflicts = errors.New("the conflicts must be resolved before merging")
+	errMergeDraft     = errors.New("drafts can't be merged")
+)
+
+// confirmModal confirms merging or closing a pull request, showing whether
+// the forge will accept a merge before it is submitted.
+type confirmModal struct {
+	keyMap confirmModalKeyMap
+	ref    services.PullRequestRef
+	kind   services.PullRequestActionKind
+	method int
+	// prechecks are the rendered lines of the mergeability checks, blocked is
+	// set when they rule out the action.
+	prechecks []string
+	blocked   error
+
+	submitting bool
+	err        error
+}
+
+func newConfirmModal(pr *services.PullRequest, kind services.PullRequestActionKind) *confirmModal {
+	modal := &confirmModal{
+		keyMap: newConfirmModalKeyMap(),
+		ref:    pr.Ref,
+		kind:   kind,
+	}
+	if kind != services.PullRequestActionClose {
+		modal.prechecks, modal.blocked = mergePrechecks(pr, kind)
+	}
+
+	return modal
+}
+
+func (m *confirmModal) merges() bool {
+	return m.kind == services.PullRequestActionMerge || m.kind == services.PullRequestActionAutoMerge
+}
+
+func (m *confirmModal) action() (services.PullRequestAction, error) {
+	if m.blocked != nil {
+		return services.PullRequestAction{}, m.blocked
+	}
+
+	action := services.PullRequestAction{Kind: m.kind}
+	if m.merges() {
+		action.MergeMethod = services.MergeMethods[m.method]
+	}
+
+	return action, nil
+}
+
+func (m *confirmModal) Update(msg tea.KeyMsg) {
+	if m.submitting {
+		return
+	}
+
+	if key.Matches(msg, m.keyMap.NextMethod) && m.merges() {
+		m.method = (m.method + 1) % len(services.MergeMethods)
+	}
+}
+
+// mergePrechecks describes whether the pull request can be merged. Auto-merge
+// exists to wait for blocking checks, so only conflicts and drafts block it.
+func mergePrechecks(pr *services.PullRequest, kind services.PullRequestActionKind) ([]string, error) {
+	var (
+		lines   = make([]string, 0)
+		blocked error
+	)
+
+	if pr.Draft {
+		lines = append(lines, checkFailureStyle.Render("✗ pull request is a draft"))
+		blocked = errMergeDraft
+	}
+
+	switch pr.MergeState {
+	case services.MergeStateClean:
+		lines = append(lines, checkSuccessStyle.Render("✓ ready to merge"))
+	case services.MergeStateConflicting:
+		lines = append(lines, checkFailureStyle.Render(fmt.Sprintf("✗ conflicts with %s", pr.BaseRef)))
+		blocked = errMergeConflicts
+	case services.MergeStateBlocked:
+		style := checkFailureStyle
+		if kind == services.PullRequestActionAutoMerge {
+			style = checkPendingStyle
+		}
+		lines = append(lines, style.Render("✗ blocked by required checks, approvals or branch rules"))
+	case services.MergeStateBehind:
+		lines = append(lines, checkPendingStyle.Render(fmt.Sprintf("● behind %s", pr.BaseRef)))
+	case services.MergeStateUnstable:
+		lines = append(lines, checkPendingStyle.Render("● checks which aren't required are failing"))
+	default:
+		lines = append(lines, checkNeutralStyle.Render("- mergeability is still being computed"))
+	}
+
+	failed := make([]string, 0)
+	pending := make([]string, 0)
+	for _, check := range pr.Checks {
+		switch {
+		case check.Failed():
+			failed = append(failed, check.Name)
+		case check.Pending():
+			pending = append(pending, check.Name)
+		}
+	}
+	if len(failed) > 0 {
+		lines = append(lines, checkFailureStyle.Render(fmt.Sprintf("✗ %d failing check(s): %s", len(failed), strings.Join(failed, ", "))))
+	}
+	if len(pending) > 0 {
+		lines = append(lines, checkPendingStyle.Render(fmt.Sprintf("● %d pending check(s): %s", len(pending), strings.Join(pending, ", "))))
+	}
+	if len(pr.Checks) > 0 && len(failed) == 0 && len(pending) == 0 {
+		lines = append(lines, checkSuccessStyle.Render(fmt.Sprintf("✓ all %d check(s) passed", len(pr.Checks))))
+	}
+
+	return lines, blocked
+}
+
+var modalSelectedStyle = lipgloss.NewStyle().Reverse(true)
+
+func (m *confirmModal) View() string {
+	lines := []string{modalTitleStyle.Render(fmt.Sprintf("%s %s?", reviewActionTitle(m.kind), m.ref))}
+
+	if m.merges() {
+		methods := make([]string, 0, len(services.MergeMethods))
+		for i, method := range services.MergeMethods {
+			if i == m.method {
+				methods = append(methods, modalSelectedStyle.Render(" "+string(method)+" "))
+				continue
+			}
+			methods = append(methods, " "+string(method)+" ")
+		}
+		lines = append(lines, "method: "+strings.Join(methods, " "))
+	}
+	lines = append(lines, m.prechecks...)
+
+	switch {
+	case m.submitting:
+		lines = append(lines, modalHintStyle.Render("submitting..."))
+	case m.err != nil:
+		lines = append(lines, modalErrorStyle.Render(m.err.Error()))
+	}
+
+	bindings := []key.Binding{m.keyMap.Confirm, m.keyMap.Cancel}
+	if m.merges() {
+		bindings = []key.Binding{m.keyMap.Confirm, m.keyMap.NextMethod, m.keyMap.Cancel}
+	}
+	hints := make([]string, 0, len(bindings))
+	for _, binding := range bindings {
+		hints = append(hints, fmt.Sprintf("%s %s", binding.Help().Key, binding.Help().Desc))
+	}
+	lines = append(lines, modalHintStyle.Render(strings.Join(hints, " • ")))
+
+	return modalStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,236 @@
+package pages
+
+import (
+	"errors"
+	"fmt"
+	"shuttle-extensions-template/internal/diff"
+	"shuttle-extensions-template/internal/services"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// diffRow maps a line of the rendered diff back to the line of the file it
+// shows. Unchanged lines exist on both sides, headers and comments on none.
+// oldPath is the path before the change, it differs for renamed files.
+type diffRow struct {
+	path    string
+	oldPath string
+	old     *diff.Line
+	new     *diff.Line
+}
+
+// target is the line a comment on the row is placed on, the new side is
+// preferred when the row has both.
+func (r diffRow) target() (services.ReviewComment, bool) {
+	comment := services.ReviewComment{Path: r.path, OldPath: r.oldPath}
+	switch {
+	case r.new != nil:
+		comment.Side, comment.Line, comment.OldLine = services.DiffSideNew, r.new.NewNumber, r.new.OldNumber
+		return comment, true
+	case r.old != nil:
+		comment.Side, comment.Line = services.DiffSideOld, r.old.OldNumber
+		return comment, true
+	default:
+		return services.ReviewComment{}, false
+	}
+}
+
+var (
+	errSelectionSpansFiles = errors.New("a comment can't span several files")
+	errSuggestionOnRemoved = errors.New("changes can only be suggested to added or unchanged lines")
+)
+
+// selectionComment returns the line comment for the lines from start to end,
+// and the current content of the new lines it covers. Lines on the new side
+// are preferred when the selection spans both.
+func selectionComment(lines []diffLine, start, end int) (services.ReviewComment, []string, error) {
+	var (
+		path     string
+		newLines = make([]services.ReviewComment, 0)
+		oldLines = make([]services.ReviewComment, 0)
+		content  = make([]string, 0)
+	)
+	for i := start; i <= end && i < len(lines); i++ {
+		target, ok := lines[i].row.target()
+		if !ok {
+			continue
+		}
+		if path != "" && target.Path != path {
+			return services.ReviewComment{}, nil, errSelectionSpansFiles
+		}
+		path = target.Path
+
+		if target.Side == services.DiffSideNew {
+			newLines = append(newLines, target)
+			content = append(content, lines[i].row.new.Content)
+		} else {
+			oldLines = append(oldLines, target)
+		}
+	}
+
+	targets := newLines
+	if len(targets) == 0 {
+		targets = oldLines
+	}
+	if len(targets) == 0 {
+		return services.ReviewComment{}, nil, errors.New("no lines selected")
+	}
+
+	comment := targets[len(targets)-1]
+	if first := targets[0]; first.Line < comment.Line {
+		comment.StartLine = first.Line
+	}
+
+	return comment, content, nil
+}
+
+type diffLineKey struct {
+	path string
+	side services.DiffSide
+	line int
+}
+
+// unifiedDiffLines maps each line of a raw unified diff onto the parsed
+// files, it returns the line each file starts on and a line to render for
+// each line of the diff. Lines of the hunks are highlighted in view, the
+// others are styled by what they are.
+func unifiedDiffLines(raw string, files []*diff.File) (map[string]int, []diffLine) {
+	lines := strings.Split(raw, "\n")
+	offsets := make(map[string]int, len(files))
+	diffLines := make([]diffLine, len(lines))
+
+	var (
+		fileIndex = -1
+		hunk      *diff.Hunk
+		hunkIndex int
+		lineIndex int
+	)
+	for i, line := range lines {
+		diffLines[i] = diffLine{kind: diffLineRaw, text: line, style: diffTextMeta}
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			fileIndex++
+			hunk, hunkIndex = nil, 0
+			if fileIndex < len(files) {
+				offsets[files[fileIndex].Path()] = i
+			}
+			diffLines[i].style = diffTextFile
+		case fileIndex < 0 || fileIndex >= len(files):
+			diffLines[i].style = rawTextStyle(line)
+		case strings.HasPrefix(line, "@@"):
+			if file := files[fileIndex]; hunkIndex < len(file.Hunks) {
+				hunk, lineIndex = &file.Hunks[hunkIndex], 0
+				hunkIndex++
+			}
+			diffLines[i].style = diffTextHunk
+		case strings.HasPrefix(line, `\`):
+			diffLines[i].style = diffTextNote
+		case hunk != nil && lineIndex < len(hunk.Lines):
+			oldIndex, newIndex := lineIndex, lineIndex
+			switch hunk.Lines[lineIndex].Kind {
+			case diff.LineAdded:
+				oldIndex = -1
+			case diff.LineRemoved:
+				newIndex = -1
+			}
+			diffLines[i] = hunkLine(diffLineCode, files[fileIndex], hunk, oldIndex, newIndex)
+			lineIndex++
+		}
+	}
+
+	return offsets, diffLines
+}
+
+// rawTextStyle styles a line of a diff which couldn't be mapped onto the
+// parsed files by its prefix.
+func rawTextStyle(line string) diffTextStyle {
+	switch {
+	case strings.HasPrefix(line, "@@"):
+		return diffTextHunk
+	case strings.HasPrefix(line, "+"):
+		return diffTextAdded
+	case strings.HasPrefix(line, "-"):
+		return diffTextRemoved
+	default:
+		return diffTextPlain
+	}
+}
+
+var (
+	inlineCommentStyle = lipgloss.NewStyle().
+				Border(lipgloss.ThickBorder(), false, false, false, true).
+				BorderForeground(lipgloss.Color("#6272A4")).
+				PaddingLeft(1).
+				MarginLeft(2)
+	pendingCommentStyle = inlineCommentStyle.Copy().
+				BorderForeground(lipgloss.Color("#F1FA8C"))
+	pendingCommentHeaderStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#F1FA8C"))
+	diffCursorStyle           = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF79C6"))
+	diffSelectionStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("#BD93F9"))
+)
+
+// layoutDiff places review comments, and the comments pending in our own
+// review, under the lines of the diff they refer to. The file offsets are
+// moved along with the lines.
+func layoutDiff(
+	diffLines []diffLine,
+	offsets map[string]int,
+	comments []services.Comment,
+	pending []services.ReviewComment,
+	width int,
+) ([]diffLine, map[string]int) {
+	blocks := make(map[diffLineKey][]string)
+	for _, comment := range comments {
+		if comment.Path == "" {
+			continue
+		}
+
+		key := diffLineKey{path: comment.Path, side: comment.Side, line: comment.Line}
+		header := fmt.Sprintf("%s · %s", comment.Author, comment.CreatedAt.Format("2006-01-02 15:04"))
+		blocks[key] = append(blocks[key], inlineCommentStyle.Copy().Width(width-3).Render(
+			commentHeaderStyle.Render(header)+"\n"+comment.Body,
+		))
+	}
+	for _, comment := range pending {
+		key := diffLineKey{path: comment.Path, side: comment.Side, line: comment.Line}
+		blocks[key] = append(blocks[key], pendingCommentStyle.Copy().Width(width-3).Render(
+			pendingCommentHeaderStyle.Render("pending")+"\n"+comment.Body,
+		))
+	}
+
+	lines := make([]diffLine, 0, len(diffLines))
+	moved := make([]int, len(diffLines))
+
+	for i, line := range diffLines {
+		moved[i] = len(lines)
+		lines = append(lines, line)
+
+		row := line.row
+
+		keys := make([]diffLineKey, 0, 2)
+		if row.old != nil {
+			keys = append(keys, diffLineKey{path: row.path, side: services.DiffSideOld, line: row.old.OldNumber})
+		}
+		if row.new != nil {
+			keys = append(keys, diffLineKey{path: row.path, side: services.DiffSideNew, line: row.new.NewNumber})
+		}
+		for _, key := range keys {
+			for _, block := range blocks[key] {
+				for _, blockLine := range strings.Split(block, "\n") {
+					lines = append(lines, diffLine{kind: diffLineText, text: blockLine})
+				}
+			}
+		}
+	}
+
+	movedOffsets := make(map[string]int, len(offsets))
+	for path, offset := range offsets {
+		if offset < len(moved) {
+			movedOffsets[path] = moved[offset]
+		}
+	}
+
+	return lines, movedOffsets
+}
@@ -0,0 +1,109 @@
+package pages
+
+import (
+	"shuttle-extensions-template/internal/diff"
+	"shuttle-extensions-template/internal/services"
+	"strings"
+	"testing"
+)
+
+const commentsTestDiff = `diff --git a/main.go b/cmd/main.go
+similarity index 90%
+rename from main.go
+rename to cmd/main.go
+--- a/main.go
++++ b/cmd/main.go
+@@ -1,3 +1,3 @@
+ package main
+-var x = 1
++var x = 2
+ var y = 3
+`
+
+func parseCommentsTestDiff(t *testing.T) ([]*diff.File, map[string]int, []diffLine) {
+	t.Helper()
+
+	files, err := diff.Parse(commentsTestDiff)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	offsets, lines := unifiedDiffLines(commentsTestDiff, files)
+
+	return files, offsets, lines
+}
+
+// describeRow returns the row as the line of the unified diff it shows.
+func describeRow(row diffRow) string {
+	switch {
+	case row.old != nil && row.new != nil:
+		return " " + row.new.Content
+	case row.old != nil:
+		return "-" + row.old.Content
+	case row.new != nil:
+		return "+" + row.new.Content
+	default:
+		return ""
+	}
+}
+
+func TestLayoutDiffPlacesCommentsBySide(t *testing.T) {
+	_, offsets, lines := parseCommentsTestDiff(t)
+
+	comments := []services.Comment{
+		{Author: "bob", Body: "old two", Path: "cmd/main.go", Side: services.DiffSideOld, Line: 2},
+		{Author: "bob", Body: "new two", Path: "cmd/main.go", Side: services.DiffSideNew, Line: 2},
+		{Author: "bob", Body: "old three", Path: "cmd/main.go", Side: services.DiffSideOld, Line: 3},
+	}
+	laidOut, _ := layoutDiff(lines, offsets, comments, nil, 80)
+
+	// Each comment is expected under the last code line before it.
+	want := map[string]string{
+		"old two":   "-var x = 1",
+		"new two":   "+var x = 2",
+		"old three": " var y = 3",
+	}
+	var code string
+	for _, line := range laidOut {
+		if line.kind == diffLineCode {
+			code = describeRow(line.row)
+			continue
+		}
+		for body, under := range want {
+			if strings.Contains(line.text, body) {
+				if code != under {
+					t.Errorf("comment %q is under %q, want %q", body, code, under)
+				}
+				delete(want, body)
+			}
+		}
+	}
+	for body := range want {
+		t.Errorf("comment %q wasn't placed", body)
+	}
+}
+
+func TestDiffRowTarget(t *testing.T) {
+	_, _, lines := parseCommentsTestDiff(t)
+
+	var targets []services.ReviewComment
+	for _, line := range lines {
+		if target, ok := line.row.target(); ok {
+			targets = append(targets, target)
+		}
+	}
+
+	want := []services.ReviewComment{
+		{Path: "cmd/main.go", OldPath: "main.go", Side: services.DiffSideNew, Line: 1, OldLine: 1},
+		{Path: "cmd/main.go", OldPath: "main.go", Side: services.DiffSideOld, Line: 2},
+		{Path: "cmd/main.go", OldPath: "main.go", Side: services.DiffSideNew, Line: 2},
+		{Path: "cmd/main.go", OldPath: "main.go", Side: services.DiffSideNew, Line: 3, OldLine: 3},
+	}
+	if len(targets) != len(want) {
+		t.Fatalf("got %d targets, want %d: %+v", len(targets), len(want), targets)
+	}
+	for i := range want {
+		if targets[i] != want[i] {
+			t.Errorf("target %d = %+v, want %+v", i, targets[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package pages
+
+import (
+	"fmt"
+	"shuttle-extensions-template/internal/diff"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	// diffGutterWidth fits a line number and a space.
+	diffGutterWidth = 5
+	// minSideBySideContentWidth is the narrowest each side of a split diff
+	// may be before falling back to a unified diff.
+	minSideBySideContentWidth = 30
+)
+
+var (
+	diffAddedBackground   = lipgloss.Color("#1E3A28")
+	diffRemovedBackground = lipgloss.Color("#3A1E24")
+	// The emphasis backgrounds mark the words which changed in a line.
+	diffAddedEmphasisBackground   = lipgloss.Color("#2F6B40")
+	diffRemovedEmphasisBackground = lipgloss.Color("#7A2E3A")
+
+	diffFileHeaderStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#BD93F9"))
+	diffHunkHeaderStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#8BE9FD"))
+	diffGutterStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4"))
+	diffAddedStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B")).Background(diffAddedBackground)
+	diffRemovedStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555")).Background(diffRemovedBackground)
+	diffEmptyStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("#44475A"))
+)
+
+// canRenderSideBySide reports whether a panel of the given width fits two
+// readable columns.
+func canRenderSideBySide(width int) bool {
+	return (width-1)/2-diffGutterWidth >= minSideBySideContentWidth
+}
+
+// renderSideBySide lays out the files as a split diff, with the old file on
+// the left and the new file on the right. Headers are rendered up front and
+// the rows once in view. It returns the lines and the line each file starts
+// on.
+func renderSideBySide(files []*diff.File, width int) ([]diffLine, map[string]int) {
+	lines := make([]diffLine, 0)
+	offsets := make(map[string]int, len(files))
+
+	add := func(line string, row diffRow) {
+		lines = append(lines, diffLine{kind: diffLineText, text: line, row: row})
+	}
+
+	for _, file := range files {
+		offsets[file.Path()] = len(lines)
+
+		title := file.Path()
+		if file.Status == diff.StatusRenamed || file.Status == diff.StatusCopied {
+			title = fmt.Sprintf("%s → %s", file.OldPath, file.NewPath)
+		}
+		title = fmt.Sprintf("%s (%s) +%d -%d", title, file.Status, file.Additions(), file.Deletions())
+		add(diffFileHeaderStyle.Render(runewidth.Truncate(title, width, "…")), diffRow{})
+
+		if file.Binary {
+			add(diffEmptyStyle.Render("binary file not shown"), diffRow{})
+			add("", diffRow{})
+			continue
+		}
+		if file.ModeChanged() {
+			add(diffEmptyStyle.Render(fmt.Sprintf("mode changed %s → %s", file.OldMode, file.NewMode)), diffRow{})
+		}
+
+		for i := range file.Hunks {
+			hunk := &file.Hunks[i]
+			add(diffHunkHeaderStyle.Render(runewidth.Truncate(hunk.Header(), width, "…")), diffRow{})
+
+			for _, row := range pairLines(hunk.Lines) {
+				lines = append(lines, hunkLine(diffLinePaired, file, hunk, row.old, row.new))
+			}
+		}
+
+		add("", diffRow{})
+	}
+
+	return lines, offsets
+}
+
+// sideBySideRow holds the indexes of the lines shown on each side, -1 for a
+// missing side.
+type sideBySideRow struct {
+	old int
+	new int
+}
+
+// pairLines aligns the lines of a hunk into rows, a run of removed lines is
+// paired with the run of added lines following it.
+func pairLines(lines []diff.Line) []sideBySideRow {
+	rows := make([]sideBySideRow, 0, len(lines))
+
+	for i := 0; i < len(lines); {
+		if lines[i].Kind == diff.LineContext {
+			rows = append(rows, sideBySideRow{old: i, new: i})
+			i++
+			continue
+		}
+
+		removed := make([]int, 0)
+		for ; i < len(lines) && lines[i].Kind == diff.LineRemoved; i++ {
+			removed = append(removed, i)
+		}
+		added := make([]int, 0)
+		for ; i < len(lines) && lines[i].Kind == diff.LineAdded; i++ {
+			added = append(added, i)
+		}
+
+		for j := 0; j < max(len(removed), len(added)); j++ {
+			row := sideBySideRow{old: -1, new: -1}
+			if j < len(removed) {
+				row.old = removed[j]
+			}
+			if j < len(added) {
+				row.new = added[j]
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
+}
+
+// renderSide renders a side of a row with the highlighted content of its
+// line.
+func renderSide(line *diff.Line, content string, contentWidth int) string {
+	if line == nil {
+		return diffEmptyStyle.Render(strings.Repeat("╱", diffGutterWidth+contentWidth))
+	}
+
+	number := line.NewNumber
+	if line.Kind == diff.LineRemoved {
+		number = line.OldNumber
+	}
+
+	content = truncateLine(content, contentWidth)
+	gutter := diffGutterStyle.Render(fmt.Sprintf("%4d ", number))
+
+	return gutter + content + diffFill(line.Kind, contentWidth-lipgloss.Width(content))
+}
+
+func diffLineStyle(kind diff.LineKind) lipgloss.Style {
+	switch kind {
+	case diff.LineAdded:
+		return diffAddedStyle
+	case diff.LineRemoved:
+		return diffRemovedStyle
+	default:
+		return lipgloss.NewStyle()
+	}
+}
+
+// diffFill pads a line to the width on the background of its kind.
+func diffFill(kind diff.LineKind, width int) string {
+	if width <= 0 {
+		return ""
+	}
+
+	return diffLineStyle(kind).Render(strings.Repeat(" ", width))
+}
+
+func expandTabs(s string) string {
+	return strings.ReplaceAll(s, "\t", "    ")
+}
@@ -0,0 +1,83 @@
+package pages
+
+import (
+	"reflect"
+	"shuttle-extensions-template/internal/diff"
+	"testing"
+)
+
+// hunkOf builds hunk lines from their prefixes, space for context, - for
+// removed and + for added.
+func hunkOf(prefixes string) []diff.Line {
+	kinds := map[rune]diff.LineKind{' ': diff.LineContext, '-': diff.LineRemoved, '+': diff.LineAdded}
+
+	lines := make([]diff.Line, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		lines = append(lines, diff.Line{Kind: kinds[prefix]})
+	}
+
+	return lines
+}
+
+func TestPairLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines string
+		want  []sideBySideRow
+	}{
+		{
+			name:  "empty",
+			lines: "",
+			want:  []sideBySideRow{},
+		},
+		{
+			name:  "context",
+			lines: "  ",
+			want:  []sideBySideRow{{old: 0, new: 0}, {old: 1, new: 1}},
+		},
+		{
+			name:  "replaced",
+			lines: " -+ ",
+			want:  []sideBySideRow{{old: 0, new: 0}, {old: 1, new: 2}, {old: 3, new: 3}},
+		},
+		{
+			name:  "more removed than added",
+			lines: "---+",
+			want:  []sideBySideRow{{old: 0, new: 3}, {old: 1, new: -1}, {old: 2, new: -1}},
+		},
+		{
+			name:  "more added than removed",
+			lines: "-+++",
+			want:  []sideBySideRow{{old: 0, new: 1}, {old: -1, new: 2}, {old: -1, new: 3}},
+		},
+		{
+			name:  "only removed",
+			lines: " --",
+			want:  []sideBySideRow{{old: 0, new: 0}, {old: 1, new: -1}, {old: 2, new: -1}},
+		},
+		{
+			name:  "only added",
+			lines: "++ ",
+			want:  []sideBySideRow{{old: -1, new: 0}, {old: -1, new: 1}, {old: 2, new: 2}},
+		},
+		{
+			name:  "context between changes",
+			lines: "-+ -+",
+			want:  []sideBySideRow{{old: 0, new: 1}, {old: 2, new: 2}, {old: 3, new: 4}},
+		},
+		{
+			name:  "added before removed",
+			lines: "+-",
+			want:  []sideBySideRow{{old: -1, new: 0}, {old: 1, new: -1}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := pairLines(hunkOf(test.lines))
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("pairLines(%q) = %+v, want %+v", test.lines, got, test.want)
+			}
+		})
+	}
+}
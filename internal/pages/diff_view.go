@@ -0,0 +1,422 @@
+package pages
+
+import (
+	"shuttle-extensions-template/internal/diff"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/chroma"
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/alecthomas/chroma/styles"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+)
+
+type diffLineKind int
+
+const (
+	// diffLineText is shown as is, it is used for headers and comments which
+	// are rendered up front.
+	diffLineText diffLineKind = iota
+	// diffLineRaw is a line of the unified diff outside of the hunks, styled
+	// once in view.
+	diffLineRaw
+	// diffLineCode is a line of a hunk in the unified diff, highlighted once in
+	// view.
+	diffLineCode
+	// diffLinePaired is a row of a side-by-side diff, rendered once in view.
+	diffLinePaired
+)
+
+// diffLine is a line of the laid out diff, only the lines in view are
+// rendered so the size of the diff doesn't matter.
+type diffLine struct {
+	kind  diffLineKind
+	row   diffRow
+	text  string
+	style diffTextStyle
+	// hunk holds the lines shown by code and paired lines, oldIndex and
+	// newIndex are their indexes in it, -1 when the side is missing.
+	hunk     *diff.Hunk
+	oldIndex int
+	newIndex int
+}
+
+func hunkLine(kind diffLineKind, file *diff.File, hunk *diff.Hunk, oldIndex, newIndex int) diffLine {
+	row := diffRow{path: file.Path(), oldPath: file.OldPath}
+	line := diffLine{kind: kind, row: row, hunk: hunk, oldIndex: oldIndex, newIndex: newIndex}
+	if oldIndex >= 0 {
+		line.row.old = &hunk.Lines[oldIndex]
+	}
+	if newIndex >= 0 {
+		line.row.new = &hunk.Lines[newIndex]
+	}
+
+	return line
+}
+
+// code returns the highlighted content of a side of the line, empty when the
+// side is missing.
+func (l diffLine) code(highlighter *diffHighlighter, index int) string {
+	if index < 0 {
+		return ""
+	}
+
+	return highlighter.line(l.row.path, l.hunk, index)
+}
+
+// diffTextStyle is how a raw line of the unified diff is styled.
+type diffTextStyle int
+
+const (
+	diffTextPlain diffTextStyle = iota
+	diffTextFile
+	diffTextMeta
+	diffTextHunk
+	diffTextAdded
+	diffTextRemoved
+	diffTextNote
+)
+
+func (s diffTextStyle) render(text string) string {
+	switch s {
+	case diffTextFile:
+		return diffFileHeaderStyle.Render(text)
+	case diffTextMeta:
+		return diffGutterStyle.Render(text)
+	case diffTextHunk:
+		return diffHunkHeaderStyle.Render(text)
+	case diffTextAdded:
+		return diffAddedStyle.Render(text)
+	case diffTextRemoved:
+		return diffRemovedStyle.Render(text)
+	case diffTextNote:
+		return diffEmptyStyle.Render(text)
+	default:
+		return text
+	}
+}
+
+// diffView shows the laid out diff, rendering the lines in view as it
+// scrolls. In cursor mode the cursor and selection are marked in a gutter.
+type diffView struct {
+	KeyMap  viewport.KeyMap
+	Width   int
+	Height  int
+	YOffset int
+
+	lines       []diffLine
+	highlighter *diffHighlighter
+
+	cursorMode     bool
+	cursor         int
+	selectionStart int
+	selectionEnd   int
+}
+
+func newDiffView(lines []diffLine, highlighter *diffHighlighter, width, height int) diffView {
+	return diffView{
+		KeyMap:      viewport.DefaultKeyMap(),
+		Width:       width,
+		Height:      height,
+		lines:       lines,
+		highlighter: highlighter,
+	}
+}
+
+// SetCursor marks the cursor and the selected lines, the gutter is hidden
+// outside of cursor mode.
+func (v *diffView) SetCursor(cursorMode bool, cursor, selectionStart, selectionEnd int) {
+	v.cursorMode = cursorMode
+	v.cursor = cursor
+	v.selectionStart = selectionStart
+	v.selectionEnd = selectionEnd
+}
+
+func (v *diffView) SetYOffset(offset int) {
+	v.YOffset = clamp(offset, 0, max(len(v.lines)-max(v.Height, 0), 0))
+}
+
+func (v diffView) Update(msg tea.Msg) (diffView, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return v, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, v.KeyMap.PageDown):
+		v.SetYOffset(v.YOffset + v.Height)
+	case key.Matches(keyMsg, v.KeyMap.PageUp):
+		v.SetYOffset(v.YOffset - v.Height)
+	case key.Matches(keyMsg, v.KeyMap.HalfPageDown):
+		v.SetYOffset(v.YOffset + v.Height/2)
+	case key.Matches(keyMsg, v.KeyMap.HalfPageUp):
+		v.SetYOffset(v.YOffset - v.Height/2)
+	case key.Matches(keyMsg, v.KeyMap.Down):
+		v.SetYOffset(v.YOffset + 1)
+	case key.Matches(keyMsg, v.KeyMap.Up):
+		v.SetYOffset(v.YOffset - 1)
+	}
+
+	return v, nil
+}
+
+func (v diffView) View() string {
+	width := v.Width
+	if v.cursorMode {
+		width--
+	}
+	paired := (width - 1) / 2
+
+	lines := make([]string, 0, max(v.Height, 0))
+	for i := v.YOffset; i < min(v.YOffset+v.Height, len(v.lines)); i++ {
+		var line string
+		switch l := v.lines[i]; l.kind {
+		case diffLineRaw:
+			line = truncateLine(l.style.render(expandTabs(l.text)), width)
+		case diffLineCode:
+			index := l.newIndex
+			if index < 0 {
+				index = l.oldIndex
+			}
+			kind := l.hunk.Lines[index].Kind
+			line = truncateLine(diffLineStyle(kind).Render(kind.Prefix())+l.code(v.highlighter, index), width)
+			line += diffFill(kind, width-lipgloss.Width(line))
+		case diffLinePaired:
+			line = renderSide(l.row.old, l.code(v.highlighter, l.oldIndex), paired-diffGutterWidth) +
+				diffGutterStyle.Render("│") +
+				renderSide(l.row.new, l.code(v.highlighter, l.newIndex), paired-diffGutterWidth)
+		default:
+			line = truncateLine(l.text, width)
+		}
+		line = "\033[0m" + line + "\033[0m"
+
+		if v.cursorMode {
+			gutter := " "
+			switch {
+			case i == v.cursor:
+				gutter = diffCursorStyle.Render("▌")
+			case i >= v.selectionStart && i <= v.selectionEnd:
+				gutter = diffSelectionStyle.Render("┃")
+			}
+			line = gutter + line
+		}
+
+		lines = append(lines, line)
+	}
+	for len(lines) < v.Height {
+		lines = append(lines, "")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func truncateLine(line string, width int) string {
+	return truncate.StringWithTail(line, uint(max(width, 0)), "…")
+}
+
+const (
+	// highlightChunkSize is how many lines of a hunk are highlighted at once,
+	// the lines around the ones in view are highlighted along with them.
+	highlightChunkSize = 128
+	// maxHighlightedChunks bounds the highlighted lines kept.
+	maxHighlightedChunks = 64
+)
+
+var codeStyle = styles.Get("dracula")
+
+type highlightChunk struct {
+	hunk  *diff.Hunk
+	start int
+}
+
+// diffHighlighter highlights the lines of the hunks with the lexer for the
+// language of their file, a chunk of lines at a time as they scroll into
+// view. It is safe to use outside of the update loop.
+type diffHighlighter struct {
+	mu sync.Mutex
+	// lexers holds the lexer of each file by its path.
+	lexers map[string]chroma.Lexer
+	chunks map[highlightChunk][]string
+	// order is the order the chunks were highlighted in, the oldest are
+	// dropped first.
+	order []highlightChunk
+}
+
+func newDiffHighlighter() *diffHighlighter {
+	return &diffHighlighter{
+		lexers: make(map[string]chroma.Lexer),
+		chunks: make(map[highlightChunk][]string),
+	}
+}
+
+// line returns the content of a line of the hunk, highlighted on the
+// background of added and removed lines.
+func (h *diffHighlighter) line(path string, hunk *diff.Hunk, i int) string {
+	if hunk == nil || i < 0 || i >= len(hunk.Lines) {
+		return ""
+	}
+
+	chunk := highlightChunk{hunk: hunk, start: i / highlightChunkSize * highlightChunkSize}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	lines, ok := h.chunks[chunk]
+	if !ok {
+		lines = highlightLines(h.lexer(path), hunk.Lines, chunk.start, min(chunk.start+highlightChunkSize, len(hunk.Lines)))
+		h.chunks[chunk] = lines
+		h.order = append(h.order, chunk)
+		if len(h.order) > maxHighlightedChunks {
+			delete(h.chunks, h.order[0])
+			h.order = h.order[1:]
+		}
+	}
+
+	return lines[i-chunk.start]
+}
+
+// lexer picks the lexer by the file name, plain text when none matches.
+func (h *diffHighlighter) lexer(path string) chroma.Lexer {
+	lexer, ok := h.lexers[path]
+	if !ok {
+		lexer = lexers.Match(path)
+		if lexer == nil {
+			lexer = lexers.Fallback
+		}
+		lexer = chroma.Coalesce(lexer)
+		h.lexers[path] = lexer
+	}
+
+	return lexer
+}
+
+// highlightLines highlights the lines of a hunk from start to end. The old and
+// the new side are highlighted apart, so each reads as the code it is in the
+// file. The words changed between removed and added lines are emphasized.
+func highlightLines(lexer chroma.Lexer, hunkLines []diff.Line, start, end int) []string {
+	lines := hunkLines[start:end]
+	changed := changedLines(hunkLines, start, end)
+
+	oldCode := make([]string, 0, len(lines))
+	newCode := make([]string, 0, len(lines))
+	for _, line := range lines {
+		content := expandTabs(line.Content)
+		if line.Kind != diff.LineAdded {
+			oldCode = append(oldCode, content)
+		}
+		if line.Kind != diff.LineRemoved {
+			newCode = append(newCode, content)
+		}
+	}
+	oldTokens, newTokens := tokeniseLines(lexer, oldCode), tokeniseLines(lexer, newCode)
+
+	var (
+		highlighted = make([]string, len(lines))
+		styles      = make(map[codeStyleKey]lipgloss.Style)
+		oldIndex    int
+		newIndex    int
+	)
+	for i, line := range lines {
+		var tokens []chroma.Token
+		switch line.Kind {
+		case diff.LineAdded:
+			tokens = newTokens[newIndex]
+			newIndex++
+		case diff.LineRemoved:
+			tokens = oldTokens[oldIndex]
+			oldIndex++
+		default:
+			tokens = newTokens[newIndex]
+			oldIndex++
+			newIndex++
+		}
+
+		var (
+			content strings.Builder
+			spans   = changed[start+i]
+			offset  int
+		)
+		for _, token := range tokens {
+			for value := token.Value; value != ""; {
+				emphasized, n := spanAt(spans, offset, len(value))
+				key := codeStyleKey{tokenType: token.Type, kind: line.Kind, emphasized: emphasized}
+				style, ok := styles[key]
+				if !ok {
+					style = codeTokenStyle(token.Type, line.Kind, emphasized)
+					styles[key] = style
+				}
+				content.WriteString(style.Render(value[:n]))
+				value, offset = value[n:], offset+n
+			}
+		}
+		highlighted[i] = content.String()
+	}
+
+	return highlighted
+}
+
+// tokeniseLines splits the tokens of the code into its lines, a line is left
+// as plain text when the code can't be tokenised.
+func tokeniseLines(lexer chroma.Lexer, code []string) [][]chroma.Token {
+	lines := make([][]chroma.Token, len(code))
+	for i, line := range code {
+		lines[i] = []chroma.Token{{Type: chroma.Text, Value: line}}
+	}
+
+	iterator, err := lexer.Tokenise(nil, strings.Join(code, "\n"))
+	if err != nil {
+		return lines
+	}
+
+	for i, tokens := range chroma.SplitTokensIntoLines(iterator.Tokens()) {
+		if i >= len(lines) {
+			break
+		}
+		trimmed := make([]chroma.Token, 0, len(tokens))
+		for _, token := range tokens {
+			token.Value = strings.TrimSuffix(token.Value, "\n")
+			if token.Value != "" {
+				trimmed = append(trimmed, token)
+			}
+		}
+		lines[i] = trimmed
+	}
+
+	return lines
+}
+
+type codeStyleKey struct {
+	tokenType  chroma.TokenType
+	kind       diff.LineKind
+	emphasized bool
+}
+
+// codeTokenStyle styles a token by the colors of the chroma style, on the
+// background of its line or a brighter one where the line changed.
+func codeTokenStyle(tokenType chroma.TokenType, kind diff.LineKind, emphasized bool) lipgloss.Style {
+	entry := codeStyle.Get(tokenType)
+	style := lipgloss.NewStyle().
+		Bold(entry.Bold == chroma.Yes).
+		Italic(entry.Italic == chroma.Yes).
+		Underline(entry.Underline == chroma.Yes)
+	if entry.Colour.IsSet() {
+		style = style.Foreground(lipgloss.Color(entry.Colour.String()))
+	}
+
+	switch {
+	case kind == diff.LineAdded && emphasized:
+		return style.Background(diffAddedEmphasisBackground)
+	case kind == diff.LineAdded:
+		return style.Background(diffAddedBackground)
+	case kind == diff.LineRemoved && emphasized:
+		return style.Background(diffRemovedEmphasisBackground)
+	case kind == diff.LineRemoved:
+		return style.Background(diffRemovedBackground)
+	default:
+		return style
+	}
+}
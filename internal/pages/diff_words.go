@@ -0,0 +1,154 @@
+package pages
+
+import (
+	"regexp"
+	"shuttle-extensions-template/internal/diff"
+	"strings"
+)
+
+// maxWordDiffCells bounds the work comparing two lines, longer lines are
+// left without their changes marked.
+const maxWordDiffCells = 1 << 16
+
+// wordRegexp splits a line into words, runs of white space and single other
+// characters, so a changed version number marks the numbers which changed.
+var wordRegexp = regexp.MustCompile(`[\p{L}\p{N}_]+|\s+|.`)
+
+// span is a range of bytes of a line.
+type span struct {
+	start int
+	end   int
+}
+
+// changedLines compares the removed and added lines shown side by side, for
+// the lines of the hunk from start to end. It returns the changed spans of
+// each line compared by its index in the hunk.
+func changedLines(lines []diff.Line, start, end int) map[int][]span {
+	changed := make(map[int][]span)
+	inRange := func(i int) bool {
+		return i >= start && i < end
+	}
+
+	// Lines are paired from the start of their run of changes, as pairLines
+	// does.
+	i := start
+	for i > 0 && lines[i-1].Kind != diff.LineContext {
+		i--
+	}
+	for i < end {
+		if lines[i].Kind == diff.LineContext {
+			i++
+			continue
+		}
+
+		removed := i
+		for ; i < len(lines) && lines[i].Kind == diff.LineRemoved; i++ {
+		}
+		added := i
+		for ; i < len(lines) && lines[i].Kind == diff.LineAdded; i++ {
+		}
+
+		for k := 0; k < min(added-removed, i-added); k++ {
+			old, new := removed+k, added+k
+			if !inRange(old) && !inRange(new) {
+				continue
+			}
+			oldSpans, newSpans := changedSpans(expandTabs(lines[old].Content), expandTabs(lines[new].Content))
+			if oldSpans != nil {
+				changed[old], changed[new] = oldSpans, newSpans
+			}
+		}
+	}
+
+	return changed
+}
+
+// changedSpans compares two lines word by word, and returns the parts of
+// each which changed. Nothing is returned when the lines have no words in
+// common, the whole line changed then.
+func changedSpans(old, new string) ([]span, []span) {
+	oldWords, newWords := wordRegexp.FindAllStringIndex(old, -1), wordRegexp.FindAllStringIndex(new, -1)
+	n, m := len(oldWords), len(newWords)
+	if n == 0 || m == 0 || (n+1)*(m+1) > maxWordDiffCells {
+		return nil, nil
+	}
+
+	word := func(line string, bounds []int) string {
+		return line[bounds[0]:bounds[1]]
+	}
+	equal := func(i, j int) bool {
+		return word(old, oldWords[i]) == word(new, newWords[j])
+	}
+
+	// common holds the length of the longest common subsequence of the words
+	// from i and j on at i*(m+1)+j.
+	common := make([]int, (n+1)*(m+1))
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if equal(i, j) {
+				common[i*(m+1)+j] = common[(i+1)*(m+1)+j+1] + 1
+			} else {
+				common[i*(m+1)+j] = max(common[(i+1)*(m+1)+j], common[i*(m+1)+j+1])
+			}
+		}
+	}
+
+	var (
+		oldSpans = make([]span, 0)
+		newSpans = make([]span, 0)
+		shared   bool
+		i, j     int
+	)
+	for i < n && j < m {
+		switch {
+		case equal(i, j):
+			shared = shared || strings.TrimSpace(word(old, oldWords[i])) != ""
+			i++
+			j++
+		case common[(i+1)*(m+1)+j] >= common[i*(m+1)+j+1]:
+			oldSpans = addSpan(oldSpans, oldWords[i])
+			i++
+		default:
+			newSpans = addSpan(newSpans, newWords[j])
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		oldSpans = addSpan(oldSpans, oldWords[i])
+	}
+	for ; j < m; j++ {
+		newSpans = addSpan(newSpans, newWords[j])
+	}
+
+	if !shared {
+		return nil, nil
+	}
+
+	return oldSpans, newSpans
+}
+
+// addSpan adds the word to the spans, joining it to the last span when they
+// touch.
+func addSpan(spans []span, bounds []int) []span {
+	if last := len(spans) - 1; last >= 0 && spans[last].end == bounds[0] {
+		spans[last].end = bounds[1]
+		return spans
+	}
+
+	return append(spans, span{start: bounds[0], end: bounds[1]})
+}
+
+// spanAt reports whether the text at the offset changed, and how many of
+// the next bytes, up to length, share that.
+func spanAt(spans []span, offset, length int) (bool, int) {
+	for _, s := range spans {
+		if offset < s.start {
+			return false, min(length, s.start-offset)
+		}
+		if offset < s.end {
+			return true, min(length, s.end-offset)
+		}
+	}
+
+	return false, length
+}
@@ -0,0 +1,183 @@
+package pages
+
+import (
+	"reflect"
+	"shuttle-extensions-template/internal/diff"
+	"testing"
+)
+
+// spanTexts returns the text of the line each span covers.
+func spanTexts(line string, spans []span) []string {
+	if spans == nil {
+		return nil
+	}
+
+	texts := make([]string, 0, len(spans))
+	for _, s := range spans {
+		texts = append(texts, line[s.start:s.end])
+	}
+
+	return texts
+}
+
+func TestChangedSpans(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     string
+		new     string
+		wantOld []string
+		wantNew []string
+	}{
+		{
+			name:    "version bump",
+			old:     "github.com/dlclark/regexp2 v1.4.0 // indirect",
+			new:     "github.com/dlclark/regexp2 v1.11.0 // indirect",
+			wantOld: []string{"4"},
+			wantNew: []string{"11"},
+		},
+		{
+			name:    "insertion",
+			old:     "call(a, b)",
+			new:     "call(a, c, b)",
+			wantOld: []string{},
+			wantNew: []string{"c, "},
+		},
+		{
+			name:    "deletion",
+			old:     "call(a, c, b)",
+			new:     "call(a, b)",
+			wantOld: []string{"c, "},
+			wantNew: []string{},
+		},
+		{
+			name: "nothing shared",
+			old:  "foo bar",
+			new:  "baz qux",
+		},
+		{
+			name: "only white space shared",
+			old:  "a b",
+			new:  "c d",
+		},
+		{
+			name: "empty line",
+			old:  "",
+			new:  "return nil",
+		},
+		{
+			name:    "multibyte",
+			old:     "café au lait",
+			new:     "café noir",
+			wantOld: []string{"au lait"},
+			wantNew: []string{"noir"},
+		},
+		{
+			name:    "multibyte added",
+			old:     "x := 1",
+			new:     "x := 2 // 漢字",
+			wantOld: []string{"1"},
+			wantNew: []string{"2 // 漢字"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			oldSpans, newSpans := changedSpans(test.old, test.new)
+			if got := spanTexts(test.old, oldSpans); !reflect.DeepEqual(got, test.wantOld) {
+				t.Errorf("old changes = %q, want %q", got, test.wantOld)
+			}
+			if got := spanTexts(test.new, newSpans); !reflect.DeepEqual(got, test.wantNew) {
+				t.Errorf("new changes = %q, want %q", got, test.wantNew)
+			}
+		})
+	}
+}
+
+func TestChangedLines(t *testing.T) {
+	lines := []diff.Line{
+		{Kind: diff.LineContext, Content: "func main() {"},
+		{Kind: diff.LineRemoved, Content: "\tx := 1"},
+		{Kind: diff.LineRemoved, Content: "\ty := 2"},
+		{Kind: diff.LineAdded, Content: "\tx := 10"},
+		{Kind: diff.LineAdded, Content: "\ty := 20"},
+		{Kind: diff.LineAdded, Content: "\tz := 30"},
+		{Kind: diff.LineContext, Content: "}"},
+	}
+
+	tests := []struct {
+		name       string
+		start, end int
+		want       map[int][]string
+	}{
+		{
+			name:  "whole hunk",
+			start: 0,
+			end:   len(lines),
+			want: map[int][]string{
+				1: {"1"}, 3: {"10"},
+				2: {"2"}, 4: {"20"},
+			},
+		},
+		{
+			name:  "from the middle of the run",
+			start: 4,
+			end:   len(lines),
+			want:  map[int][]string{2: {"2"}, 4: {"20"}},
+		},
+		{
+			name:  "only the unpaired added line",
+			start: 5,
+			end:   len(lines),
+			want:  map[int][]string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changed := changedLines(lines, test.start, test.end)
+
+			got := make(map[int][]string, len(changed))
+			for i, spans := range changed {
+				got[i] = spanTexts(expandTabs(lines[i].Content), spans)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("changedLines(%d, %d) = %q, want %q", test.start, test.end, got, test.want)
+			}
+		})
+	}
+}
+
+func TestAddSpan(t *testing.T) {
+	spans := addSpan(nil, []int{0, 2})
+	spans = addSpan(spans, []int{2, 4})
+	spans = addSpan(spans, []int{6, 7})
+
+	if want := []span{{start: 0, end: 4}, {start: 6, end: 7}}; !reflect.DeepEqual(spans, want) {
+		t.Errorf("spans = %+v, want %+v", spans, want)
+	}
+}
+
+func TestSpanAt(t *testing.T) {
+	spans := []span{{start: 2, end: 4}, {start: 6, end: 9}}
+
+	tests := []struct {
+		offset, length int
+		changed        bool
+		n              int
+	}{
+		{offset: 0, length: 10, changed: false, n: 2},
+		{offset: 0, length: 1, changed: false, n: 1},
+		{offset: 2, length: 10, changed: true, n: 2},
+		{offset: 3, length: 10, changed: true, n: 1},
+		{offset: 4, length: 10, changed: false, n: 2},
+		{offset: 7, length: 1, changed: true, n: 1},
+		{offset: 9, length: 5, changed: false, n: 5},
+	}
+
+	for _, test := range tests {
+		changed, n := spanAt(spans, test.offset, test.length)
+		if changed != test.changed || n != test.n {
+			t.Errorf("spanAt(%d, %d) = %v, %d, want %v, %d", test.offset, test.length, changed, n, test.changed, test.n)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package pages
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// editorFinishedMsg is sent when the external editor has exited, content is
+// the edited text.
+type editorFinishedMsg struct {
+	content string
+	err     error
+}
+
+// openEditor suspends the program and edits the content in $EDITOR, falling
+// back to vi.
+func openEditor(content string) tea.Cmd {
+	file, err := os.CreateTemp("", "dr-*.md")
+	if err != nil {
+		return func() tea.Msg {
+			return editorFinishedMsg{err: err}
+		}
+	}
+	path := file.Name()
+
+	_, err = file.WriteString(content)
+	err = errors.Join(err, file.Close())
+	if err != nil {
+		os.Remove(path)
+		return func() tea.Msg {
+			return editorFinishedMsg{err: err}
+		}
+	}
+
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			return editorFinishedMsg{err: err}
+		}
+
+		edited, err := os.ReadFile(path)
+		if err != nil {
+			return editorFinishedMsg{err: err}
+		}
+
+		return editorFinishedMsg{content: strings.TrimSuffix(string(edited), "\n")}
+	})
+}
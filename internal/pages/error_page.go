@@ -0,0 +1,146 @@
+package pages
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const ErrorPage = "error"
+
+// ErrorMsg shows the error page in place of the current page. Retry is run
+// when the user retries, the page only offers going back when it is nil.
+type ErrorMsg struct {
+	Title string
+	Err   error
+	Retry tea.Cmd
+}
+
+func ShowError(title string, err error, retry tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		return ErrorMsg{Title: title, Err: err, Retry: retry}
+	}
+}
+
+// recoverCmd runs the command, turning a panic into the message failed makes
+// of it. Commands run in their own goroutines, out of reach of the recover
+// around the program.
+func recoverCmd(cmd tea.Cmd, failed func(err error) tea.Msg) tea.Cmd {
+	return func() (msg tea.Msg) {
+		defer func() {
+			if r := recover(); r != nil {
+				msg = failed(fmt.Errorf("%v", r))
+			}
+		}()
+
+		return cmd()
+	}
+}
+
+type errorKeyMap struct {
+	Retry key.Binding
+	Back  key.Binding
+	Quit  key.Binding
+}
+
+func newErrorKeyMap() errorKeyMap {
+	return errorKeyMap{
+		Retry: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "retry"),
+		),
+		Back: key.NewBinding(
+			key.WithKeys("b", "esc"),
+			key.WithHelp("esc", "back"),
+		),
+		Quit: key.NewBinding(
+			key.WithKeys("q"),
+			key.WithHelp("q", "quit"),
+		),
+	}
+}
+
+func (e errorKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{e.Retry, e.Back, e.Quit}
+}
+
+func (e errorKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{e.ShortHelp()}
+}
+
+type Error struct {
+	keyMap errorKeyMap
+	help   help.Model
+	msg    ErrorMsg
+
+	width, height int
+}
+
+func NewError() *Error {
+	return &Error{
+		keyMap: newErrorKeyMap(),
+		help:   help.New(),
+	}
+}
+
+func (e *Error) Init() tea.Cmd {
+	return nil
+}
+
+func (e *Error) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ErrorMsg:
+		e.msg = msg
+		e.keyMap.Retry.SetEnabled(msg.Retry != nil)
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, e.keyMap.Retry):
+			return e, tea.Sequence(NewPopPage(), e.msg.Retry)
+		case key.Matches(msg, e.keyMap.Back):
+			return e, NewPopPage()
+		}
+	}
+
+	return e, nil
+}
+
+var (
+	errorTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FF5555"))
+	errorBoxStyle   = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("#FF5555")).
+			Padding(1, 2)
+)
+
+func (e *Error) View() string {
+	title := e.msg.Title
+	if title == "" {
+		title = "Something went wrong"
+	}
+
+	details := "unknown error"
+	if e.msg.Err != nil {
+		details = e.msg.Err.Error()
+	}
+
+	box := errorBoxStyle.Copy().Width(min(e.width-4, 80)).Render(
+		lipgloss.JoinVertical(
+			lipgloss.Left,
+			errorTitleStyle.Render(title),
+			"",
+			details,
+			"",
+			e.help.View(e.keyMap),
+		),
+	)
+
+	return docStyle.Render(lipgloss.Place(e.width, e.height, lipgloss.Center, lipgloss.Center, box))
+}
+
+func (e *Error) SetSize(width, height int) {
+	e.width = width
+	e.height = height
+}
@@ -0,0 +1,291 @@
+package pages
+
+import (
+	"fmt"
+	"shuttle-extensions-template/internal/diff"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type fileTreeKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Select key.Binding
+	Expand key.Binding
+	Fold   key.Binding
+}
+
+func newFileTreeKeyMap() fileTreeKeyMap {
+	return fileTreeKeyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "previous file"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "next file"),
+		),
+		Select: key.NewBinding(
+			key.WithKeys("enter", " "),
+			key.WithHelp("enter", "open file or toggle directory"),
+		),
+		Expand: key.NewBinding(
+			key.WithKeys("right", "l"),
+			key.WithHelp("→/l", "expand directory"),
+		),
+		Fold: key.NewBinding(
+			key.WithKeys("left", "h"),
+			key.WithHelp("←/h", "collapse directory"),
+		),
+	}
+}
+
+// fileSelectedMsg is sent when a file is opened in the file tree.
+type fileSelectedMsg struct {
+	path string
+}
+
+type fileTreeNode struct {
+	name      string
+	path      string
+	dir       bool
+	depth     int
+	additions int
+	deletions int
+	status    diff.FileStatus
+	children  []*fileTreeNode
+}
+
+// fileTree is a collapsible tree of the files changed by a pull request.
+type fileTree struct {
+	keyMap    fileTreeKeyMap
+	root      *fileTreeNode
+	collapsed map[string]bool
+	visible   []*fileTreeNode
+	cursor    int
+	offset    int
+
+	width, height int
+}
+
+func newFileTree(files []*diff.File) fileTree {
+	root := &fileTreeNode{dir: true, depth: -1}
+
+	for _, file := range files {
+		parent := root
+		parts := strings.Split(file.Path(), "/")
+		for i, part := range parts {
+			path := strings.Join(parts[:i+1], "/")
+			last := i == len(parts)-1
+
+			var node *fileTreeNode
+			for _, child := range parent.children {
+				if child.name == part && child.dir != last {
+					node = child
+					break
+				}
+			}
+			if node == nil {
+				node = &fileTreeNode{
+					name:  part,
+					path:  path,
+					dir:   !last,
+					depth: parent.depth + 1,
+				}
+				parent.children = append(parent.children, node)
+			}
+			if last {
+				node.status = file.Status
+			}
+
+			node.additions += file.Additions()
+			node.deletions += file.Deletions()
+			parent = node
+		}
+	}
+	sortFileTree(root)
+
+	tree := fileTree{
+		keyMap:    newFileTreeKeyMap(),
+		root:      root,
+		collapsed: make(map[string]bool),
+	}
+	tree.refresh()
+
+	return tree
+}
+
+// sortFileTree lists directories before files, like most editors do.
+func sortFileTree(node *fileTreeNode) {
+	sort.SliceStable(node.children, func(i, j int) bool {
+		a, b := node.children[i], node.children[j]
+		if a.dir != b.dir {
+			return a.dir
+		}
+
+		return a.name < b.name
+	})
+
+	for _, child := range node.children {
+		sortFileTree(child)
+	}
+}
+
+func (t *fileTree) refresh() {
+	t.visible = t.visible[:0]
+
+	var walk func(node *fileTreeNode)
+	walk = func(node *fileTreeNode) {
+		for _, child := range node.children {
+			t.visible = append(t.visible, child)
+			if child.dir && !t.collapsed[child.path] {
+				walk(child)
+			}
+		}
+	}
+	walk(t.root)
+
+	t.cursor = clamp(t.cursor, 0, max(len(t.visible)-1, 0))
+	t.scroll()
+}
+
+func (t *fileTree) scroll() {
+	if t.height <= 0 {
+		return
+	}
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.cursor >= t.offset+t.height {
+		t.offset = t.cursor - t.height + 1
+	}
+}
+
+func (t *fileTree) SetSize(width, height int) {
+	t.width = width
+	t.height = height
+	t.scroll()
+}
+
+func (t fileTree) Update(msg tea.Msg) (fileTree, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || len(t.visible) == 0 {
+		return t, nil
+	}
+
+	node := t.visible[t.cursor]
+
+	switch {
+	case key.Matches(keyMsg, t.keyMap.Up):
+		t.cursor = max(t.cursor-1, 0)
+	case key.Matches(keyMsg, t.keyMap.Down):
+		t.cursor = min(t.cursor+1, len(t.visible)-1)
+	case key.Matches(keyMsg, t.keyMap.Select):
+		if node.dir {
+			t.collapsed[node.path] = !t.collapsed[node.path]
+			t.refresh()
+			return t, nil
+		}
+
+		return t, func() tea.Msg {
+			return fileSelectedMsg{path: node.path}
+		}
+	case key.Matches(keyMsg, t.keyMap.Expand):
+		if node.dir {
+			t.collapsed[node.path] = false
+			t.refresh()
+		}
+	case key.Matches(keyMsg, t.keyMap.Fold):
+		if node.dir && !t.collapsed[node.path] {
+			t.collapsed[node.path] = true
+			t.refresh()
+			return t, nil
+		}
+		// Jump to the parent directory.
+		for i := t.cursor - 1; i >= 0; i-- {
+			if t.visible[i].dir && t.visible[i].depth < node.depth {
+				t.cursor = i
+				break
+			}
+		}
+	}
+
+	t.scroll()
+
+	return t, nil
+}
+
+var (
+	fileTreeCursorStyle    = lipgloss.NewStyle().Reverse(true)
+	fileTreeDirectoryStyle = lipgloss.NewStyle().Bold(true)
+	fileTreeAddedStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B"))
+	fileTreeDeletedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555"))
+)
+
+func (t fileTree) View() string {
+	if len(t.visible) == 0 {
+		return "no files changed"
+	}
+
+	end := min(t.offset+t.height, len(t.visible))
+	lines := make([]string, 0, end-t.offset)
+
+	for i := t.offset; i < end; i++ {
+		node := t.visible[i]
+
+		icon := "  "
+		if node.dir {
+			icon = "▾ "
+			if t.collapsed[node.path] {
+				icon = "▸ "
+			}
+		}
+
+		name := strings.Repeat("  ", node.depth) + icon + node.name
+		if node.dir {
+			name += "/"
+		}
+		if node.status == diff.StatusAdded || node.status == diff.StatusDeleted || node.status == diff.StatusRenamed {
+			name += fmt.Sprintf(" (%s)", node.status)
+		}
+
+		counts := fmt.Sprintf(" +%d -%d", node.additions, node.deletions)
+		name = truncateWidth(name, t.width-lipgloss.Width(counts))
+		padding := max(t.width-lipgloss.Width(name)-lipgloss.Width(counts), 0)
+
+		if i == t.cursor {
+			lines = append(lines, fileTreeCursorStyle.Render(name+strings.Repeat(" ", padding)+counts))
+			continue
+		}
+
+		if node.dir {
+			name = fileTreeDirectoryStyle.Render(name)
+		}
+		lines = append(lines, name+strings.Repeat(" ", padding)+
+			fileTreeAddedStyle.Render(fmt.Sprintf(" +%d", node.additions))+
+			fileTreeDeletedStyle.Render(fmt.Sprintf(" -%d", node.deletions)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func truncateWidth(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+
+	runes := []rune(s)
+	for lipgloss.Width(string(runes)) > width && len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+	}
+
+	return string(runes)
+}
+
+func clamp(v, lower, upper int) int {
+	return min(max(v, lower), upper)
+}
@@ -0,0 +1,191 @@
+package pages
+
+import (
+	"fmt"
+	"shuttle-extensions-template/internal/services"
+	"sort"
+	"strings"
+	"time"
+)
+
+// tableColumn is a column of the pull request table, less orders the pull
+// requests when sorting on it.
+type tableColumn struct {
+	title string
+	// width is the minimum width, the title column takes the space left.
+	width int
+	value func(pr *services.PullRequest) string
+	less  func(a, b *services.PullRequest) bool
+}
+
+const titleColumn = 1
+
+var tableColumns = []tableColumn{
+	{
+		title: "Repo",
+		width: 20,
+		value: func(pr *services.PullRequest) string { return pr.Ref.String() },
+		less: func(a, b *services.PullRequest) bool {
+			if a.Ref.Repo != b.Ref.Repo {
+				return a.Ref.Repo < b.Ref.Repo
+			}
+
+			return a.Ref.Number < b.Ref.Number
+		},
+	},
+	{
+		title: "Title",
+		width: 20,
+		value: func(pr *services.PullRequest) string {
+			if pr.Draft {
+				return "[draft] " + pr.Title
+			}
+
+			return pr.Title
+		},
+		less: func(a, b *services.PullRequest) bool {
+			return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+		},
+	},
+	{
+		title: "Author",
+		width: 16,
+		value: func(pr *services.PullRequest) string { return pr.Author },
+		less: func(a, b *services.PullRequest) bool {
+			return strings.ToLower(a.Author) < strings.ToLower(b.Author)
+		},
+	},
+	{
+		title: "Age",
+		width: 5,
+		value: func(pr *services.PullRequest) string { return formatAge(time.Since(pr.CreatedAt)) },
+		less: func(a, b *services.PullRequest) bool {
+			return a.CreatedAt.After(b.CreatedAt)
+		},
+	},
+	{
+		title: "+/-",
+		width: 12,
+		value: func(pr *services.PullRequest) string {
+			additions, deletions := pr.Size()
+			return fmt.Sprintf("+%d -%d", additions, deletions)
+		},
+		less: func(a, b *services.PullRequest) bool {
+			aAdditions, aDeletions := a.Size()
+			bAdditions, bDeletions := b.Size()
+
+			return aAdditions+aDeletions < bAdditions+bDeletions
+		},
+	},
+	{
+		title: "Checks",
+		width: 16,
+		value: func(pr *services.PullRequest) string { return summarizeChecks(pr.Checks) },
+		less: func(a, b *services.PullRequest) bool {
+			return checksRank(a.Checks) < checksRank(b.Checks)
+		},
+	},
+	{
+		title: "Approvals",
+		width: 19,
+		value: func(pr *services.PullRequest) string { return describeReviewState(pr.ReviewState) },
+		less: func(a, b *services.PullRequest) bool {
+			return reviewRank(a.ReviewState) < reviewRank(b.ReviewState)
+		},
+	},
+}
+
+// sortPullRequests sorts the pull requests on the column, the sort is stable
+// so ties keep their previous order.
+func sortPullRequests(prs []*services.PullRequest, column int, descending bool) {
+	less := tableColumns[column].less
+	sort.SliceStable(prs, func(i, j int) bool {
+		if descending {
+			return less(prs[j], prs[i])
+		}
+
+		return less(prs[i], prs[j])
+	})
+}
+
+// formatAge shortens the age to its largest unit, such as 3d.
+func formatAge(age time.Duration) string {
+	switch {
+	case age >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	case age >= time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	}
+}
+
+type checksState int
+
+const (
+	checksNone checksState = iota
+	checksPassed
+	checksPending
+	checksFailing
+)
+
+func checksRank(checks []services.Check) checksState {
+	state := checksNone
+	for _, check := range checks {
+		switch {
+		case check.Failed():
+			return checksFailing
+		case check.Pending():
+			state = checksPending
+		case state == checksNone:
+			state = checksPassed
+		}
+	}
+
+	return state
+}
+
+func summarizeChecks(checks []services.Check) string {
+	var failed, pending int
+	for _, check := range checks {
+		switch {
+		case check.Failed():
+			failed++
+		case check.Pending():
+			pending++
+		}
+	}
+
+	switch {
+	case len(checks) == 0:
+		return "- no checks"
+	case failed > 0:
+		return fmt.Sprintf("✗ %d failing", failed)
+	case pending > 0:
+		return fmt.Sprintf("● %d pending", pending)
+	default:
+		return "✓ passed"
+	}
+}
+
+func reviewRank(state services.ReviewState) int {
+	switch state {
+	case services.ReviewStateApproved:
+		return 2
+	case services.ReviewStateChangesRequested:
+		return 0
+	default:
+		return 1
+	}
+}
+
+func describeReviewState(state services.ReviewState) string {
+	switch state {
+	case services.ReviewStateApproved:
+		return "✓ approved"
+	case services.ReviewStateChangesRequested:
+		return "✗ changes requested"
+	default:
+		return "● pending"
+	}
+}
@@ -0,0 +1,168 @@
+package pages
+
+import (
+	"context"
+	"shuttle-extensions-template/internal/diff"
+	"shuttle-extensions-template/internal/services"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// prefetchCount is how many pull requests are fetched ahead of the one being
+// reviewed, so skipping to the next one is instant.
+const prefetchCount = 3
+
+// loadedPr is a pull request prepared for rendering, its diff and
+// description are rendered ahead into the render cache outside of the update
+// loop. The diff is highlighted as it scrolls into view.
+type loadedPr struct {
+	pr          *services.PullRequest
+	files       []*diff.File
+	parseErr    error
+	highlighter *diffHighlighter
+
+	diffHash        uint64
+	descriptionHash uint64
+}
+
+func loadPr(renders *renderCache, pr *services.PullRequest, descriptionWidth int) *loadedPr {
+	loaded := &loadedPr{
+		pr:              pr,
+		highlighter:     newDiffHighlighter(),
+		diffHash:        hashContent(pr.Diff),
+		descriptionHash: hashContent(pr.Description),
+	}
+	// Providers parse the diff as they fetch it, it is only parsed again to
+	// tell why it couldn't be.
+	loaded.files = pr.Files
+	if loaded.files == nil {
+		loaded.files, loaded.parseErr = diff.Parse(pr.Diff)
+		if loaded.parseErr != nil {
+			loaded.files = nil
+		}
+	}
+
+	renders.diff(loaded, 0, false)
+	if len(loaded.files) > 0 && len(loaded.files[0].Hunks) > 0 {
+		loaded.highlighter.line(loaded.files[0].Path(), &loaded.files[0].Hunks[0], 0)
+	}
+	_, _ = renders.description(loaded, descriptionWidth)
+
+	return loaded
+}
+
+// prFetchedMsg is sent when the next pull request of the queue has been
+// fetched, ok is false when the queue is empty. provider is the queue it was
+// fetched from, results from a previous queue are dropped.
+type prFetchedMsg struct {
+	provider services.PullRequestProvider
+	pr       *loadedPr
+	ok       bool
+	err      error
+}
+
+func (prFetchedMsg) TargetPage() string {
+	return PullRequestReviewPage
+}
+
+var _ PageMsg = prFetchedMsg{}
+
+// fetchNext gets the next pull request of the queue and prepares it for
+// rendering at the width of the description panel, a panic is reported as an
+// error.
+func fetchNext(ctx context.Context, provider services.PullRequestProvider, renders *renderCache, descriptionWidth int) tea.Cmd {
+	return recoverCmd(func() tea.Msg {
+		pr, ok, err := provider.GetNext(ctx)
+		if err != nil || !ok {
+			return prFetchedMsg{provider: provider, ok: ok, err: err}
+		}
+
+		return prFetchedMsg{provider: provider, pr: loadPr(renders, pr, descriptionWidth), ok: true}
+	}, func(err error) tea.Msg {
+		return prFetchedMsg{provider: provider, err: err}
+	})
+}
+
+// prOpenedMsg is sent when a pull request opened from outside the queue has
+// been prepared for rendering, or failed to be.
+type prOpenedMsg struct {
+	opened *services.PullRequest
+	pr     *loadedPr
+	err    error
+}
+
+func (prOpenedMsg) TargetPage() string {
+	return PullRequestReviewPage
+}
+
+var _ PageMsg = prOpenedMsg{}
+
+func loadOpened(pr *services.PullRequest, renders *renderCache, descriptionWidth int) tea.Cmd {
+	return recoverCmd(func() tea.Msg {
+		return prOpenedMsg{opened: pr, pr: loadPr(renders, pr, descriptionWidth)}
+	}, func(err error) tea.Msg {
+		return prOpenedMsg{opened: pr, err: err}
+	})
+}
+
+// renderedMsg is sent when the panels of the pull request have been rendered
+// outside of the update loop, for the inputs given.
+type renderedMsg struct {
+	pr             *loadedPr
+	inputs         renderInputs
+	diff           diffLayout
+	description    string
+	descriptionErr error
+	err            error
+}
+
+func (renderedMsg) TargetPage() string {
+	return PullRequestReviewPage
+}
+
+var _ PageMsg = renderedMsg{}
+
+// renderInputs are what the panels are rendered from besides the pull
+// request, a render is stale once they change.
+type renderInputs struct {
+	diffWidth        int
+	descriptionWidth int
+	sideBySide       bool
+	pendingComments  int
+}
+
+// diffLayout is the diff laid out for the diff panel, with the review
+// comments placed under their lines.
+type diffLayout struct {
+	lines   []diffLine
+	offsets map[string]int
+}
+
+func layoutPrDiff(renders *renderCache, loaded *loadedPr, width int, sideBySide bool, pending []services.ReviewComment) diffLayout {
+	content := renders.diff(loaded, width, sideBySide)
+	lines, offsets := layoutDiff(
+		content.lines, content.offsets,
+		loaded.pr.Comments, pending,
+		width,
+	)
+
+	return diffLayout{lines: lines, offsets: offsets}
+}
+
+// renderPr renders the panels outside of the update loop, a panic while
+// rendering is reported as an error rather than taking down the session.
+func renderPr(renders *renderCache, loaded *loadedPr, inputs renderInputs, pending []services.ReviewComment) tea.Cmd {
+	return recoverCmd(func() tea.Msg {
+		description, descriptionErr := renders.description(loaded, inputs.descriptionWidth)
+
+		return renderedMsg{
+			pr:             loaded,
+			inputs:         inputs,
+			diff:           layoutPrDiff(renders, loaded, inputs.diffWidth, inputs.sideBySide, pending),
+			description:    description,
+			descriptionErr: descriptionErr,
+		}
+	}, func(err error) tea.Msg {
+		return renderedMsg{pr: loaded, inputs: inputs, err: err}
+	})
+}
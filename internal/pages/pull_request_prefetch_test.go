@@ -0,0 +1,61 @@
+package pages
+
+import (
+	"context"
+	"shuttle-extensions-template/internal/services"
+	"strings"
+	"testing"
+)
+
+// panickingProvider panics when asked for the next pull request.
+type panickingProvider struct {
+	services.PullRequestProvider
+}
+
+func (panickingProvider) GetNext(ctx context.Context) (*services.PullRequest, bool, error) {
+	panic("provider broke")
+}
+
+func TestFetchNextRecovers(t *testing.T) {
+	provider := panickingProvider{}
+
+	msg, ok := fetchNext(context.Background(), provider, newRenderCache(), 80)().(prFetchedMsg)
+	if !ok {
+		t.Fatal("fetchNext() didn't return a prFetchedMsg")
+	}
+	if msg.err == nil || !strings.Contains(msg.err.Error(), "provider broke") {
+		t.Errorf("err = %v, want the panic", msg.err)
+	}
+	if msg.provider != provider {
+		t.Error("the message isn't tied to the provider it was fetched from")
+	}
+}
+
+func TestLoadOpenedRecovers(t *testing.T) {
+	pr := &services.PullRequest{Ref: services.PullRequestRef{Repo: "lunarway/dr", Number: 7}}
+
+	// Without a render cache preparing the pull request panics.
+	msg, ok := loadOpened(pr, nil, 80)().(prOpenedMsg)
+	if !ok {
+		t.Fatal("loadOpened() didn't return a prOpenedMsg")
+	}
+	if msg.err == nil || msg.opened != pr {
+		t.Errorf("loadOpened() = %+v, want an error for the opened pull request", msg)
+	}
+}
+
+func TestLoadOpened(t *testing.T) {
+	demo := services.NewDemoPullRequestProvider()
+	pr, err := demo.Get(context.Background(), services.PullRequestRef{Repo: "lunarway/demo", Number: 1})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	msg := loadOpened(pr, newRenderCache(), 80)().(prOpenedMsg)
+	if msg.err != nil {
+		t.Fatalf("loadOpened() error = %v", msg.err)
+	}
+	if msg.opened != pr || msg.pr.pr != pr || len(msg.pr.files) == 0 {
+		t.Errorf("loadOpened() = %+v, want the pull request prepared", msg)
+	}
+}
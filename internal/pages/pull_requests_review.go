@@ -1,26 +1,43 @@
 package pages
 
 import (
-	"bytes"
+	"context"
+	"fmt"
+	"shuttle-extensions-template/internal/diff"
+	"shuttle-extensions-template/internal/notifications"
 	"shuttle-extensions-template/internal/services"
 	"shuttle-extensions-template/internal/utility"
+	"slices"
 	"strings"
 
-	"github.com/alecthomas/chroma/quick"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
 )
 
 const PullRequestReviewPage = "pull_request_review"
 
 type reviewKeyMap struct {
-	Skip    key.Binding
-	TabNext key.Binding
-	Help    key.Binding
+	Skip           key.Binding
+	Approve        key.Binding
+	RequestChanges key.Binding
+	Comment        key.Binding
+	Merge          key.Binding
+	AutoMerge      key.Binding
+	Close          key.Binding
+	TabNext        key.Binding
+	SideBySide     key.Binding
+	LineCursor     key.Binding
+	CursorUp       key.Binding
+	CursorDown     key.Binding
+	LineComment    key.Binding
+	SelectRange    key.Binding
+	Suggest        key.Binding
+	Back           key.Binding
+	Help           key.Binding
 }
 
 func (r reviewKeyMap) FullHelp() [][]key.Binding {
@@ -28,8 +45,26 @@ func (r reviewKeyMap) FullHelp() [][]key.Binding {
 		{
 			r.Skip,
 			r.TabNext,
+			r.SideBySide,
 		},
 		{
+			r.Approve,
+			r.RequestChanges,
+			r.Comment,
+		},
+		{
+			r.Merge,
+			r.AutoMerge,
+			r.Close,
+		},
+		{
+			r.LineCursor,
+			r.LineComment,
+			r.SelectRange,
+			r.Suggest,
+		},
+		{
+			r.Back,
 			r.Help,
 		},
 	}
@@ -38,6 +73,10 @@ func (r reviewKeyMap) FullHelp() [][]key.Binding {
 func (r reviewKeyMap) ShortHelp() []key.Binding {
 	return []key.Binding{
 		r.Skip,
+		r.Approve,
+		r.RequestChanges,
+		r.Comment,
+		r.LineCursor,
 		r.Help,
 	}
 }
@@ -48,10 +87,65 @@ func newReviewKeyMap() reviewKeyMap {
 			key.WithKeys("s"),
 			key.WithHelp("s", "skip the current pr"),
 		),
+		Approve: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "approve"),
+		),
+		RequestChanges: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "request changes"),
+		),
+		Comment: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "comment"),
+		),
+		Merge: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "merge"),
+		),
+		AutoMerge: key.NewBinding(
+			key.WithKeys("M"),
+			key.WithHelp("M", "enable auto-merge"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("X"),
+			key.WithHelp("X", "close"),
+		),
 		TabNext: key.NewBinding(
 			key.WithKeys("tab"),
 			key.WithHelp("tab", "switch to next interactive panel"),
 		),
+		SideBySide: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "toggle side-by-side diff"),
+		),
+		LineCursor: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "toggle line cursor"),
+		),
+		CursorUp: key.NewBinding(
+			key.WithKeys("up", "k"),
+		),
+		CursorDown: key.NewBinding(
+			key.WithKeys("down", "j"),
+		),
+		LineComment: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "comment on line"),
+		),
+		SelectRange: key.NewBinding(
+			key.WithKeys("V"),
+			key.WithHelp("V", "select line range"),
+		),
+		Suggest: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "suggest a change"),
+		),
+		// Back is handled by the app, it is only listed for the help.
+		Back: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back"),
+		),
 		Help: key.NewBinding(
 			key.WithKeys("?"),
 			key.WithHelp("?", "toggle help"),
@@ -59,37 +153,496 @@ func newReviewKeyMap() reviewKeyMap {
 	}
 }
 
+const (
+	focusDescription = iota
+	focusFiles
+	focusDiff
+
+	focusCount
+)
+
 type PullRequestReview struct {
 	keyMap      reviewKeyMap
 	help        help.Model
-	diff        viewport.Model
+	diff        diffView
 	description viewport.Model
+	files       fileTree
+
+	diffFiles []*diff.File
+	// diffLines are the lines of the laid out diff, including inline
+	// comments.
+	diffLines []diffLine
+	// fileOffsets are the lines in the laid out diff where each file starts.
+	fileOffsets map[string]int
+	sideBySide  bool
+
+	cursorMode bool
+	// cursor is the line of the rendered diff selected in cursor mode, when
+	// selecting the selection reaches from anchor to the cursor.
+	cursor    int
+	selecting bool
+	anchor    int
+	// pendingComments are line comments submitted with the next review.
+	pendingComments []services.ReviewComment
+
+	ctx      context.Context
+	provider services.PullRequestProvider
 
-	githubPrService *services.GitHubPullRequestService
+	modal   *reviewModal
+	confirm *confirmModal
+
+	// loading is set while waiting for the next pull request, which is
+	// fetched along with the ones prefetched behind it one at a time.
+	loading    bool
+	fetching   bool
+	exhausted  bool
+	prefetched []*loadedPr
+	spinner    spinner.Model
+	// opening is the pull request opened from outside the queue while it is
+	// prepared for rendering.
+	opening *services.PullRequest
+	renders *renderCache
 
 	ready         bool
 	width, height int
-	currentPr     *services.GitHubPullRequest
+	currentPr     *services.PullRequest
+	loaded        *loadedPr
 	focus         int
 }
 
-func NewPullRequestReview() *PullRequestReview {
+func NewPullRequestReview(ctx context.Context, provider services.PullRequestProvider) *PullRequestReview {
 	return &PullRequestReview{
 		keyMap: newReviewKeyMap(),
 		help:   help.New(),
 
-		githubPrService: services.NewGitHubPullRequestService(),
+		ctx:      ctx,
+		provider: provider,
+		spinner:  spinner.New(spinner.WithSpinner(spinner.Dot)),
+		renders:  newRenderCache(),
 
 		currentPr: nil,
-		focus:     0,
+		focus:     focusDescription,
+	}
+}
+
+// setPr shows the pull request. A diff which can't be parsed is still shown,
+// but its files can't be browsed or commented on.
+func (p *PullRequestReview) setPr(loaded *loadedPr) error {
+	p.reset()
+	p.currentPr = loaded.pr
+	p.loaded = loaded
+	p.diffFiles = loaded.files
+	p.files = newFileTree(loaded.files)
+
+	return loaded.parseErr
+}
+
+// reset clears the pull request being reviewed along with the review in
+// progress.
+func (p *PullRequestReview) reset() {
+	p.currentPr = nil
+	p.loaded = nil
+	p.opening = nil
+	p.diff = diffView{}
+	p.description = viewport.New(0, 0)
+	p.diffFiles = nil
+	p.diffLines = nil
+	p.files = newFileTree(nil)
+	p.cursorMode = false
+	p.cursor = 0
+	p.selecting = false
+	p.pendingComments = nil
+	p.ready = false
+}
+
+// renderDiff lays out the diff of the current pull request again, for when
+// the cursor or the pending comments change.
+func (p *PullRequestReview) renderDiff() {
+	p.setDiff(layoutPrDiff(p.renders, p.loaded, p.diffWidth(), p.sideBySide, p.pendingComments))
+}
+
+// setDiff shows the diff laid out, side-by-side if enabled and the diff panel
+// is wide enough, with review comments inline.
+func (p *PullRequestReview) setDiff(layout diffLayout) {
+	target, hasTarget := p.cursorTarget()
+	p.diffLines, p.fileOffsets = layout.lines, layout.offsets
+
+	// Lines move when rendering again, the cursor is kept on its line but the
+	// selection is dropped.
+	p.selecting = false
+	p.cursor = clamp(p.cursor, 0, max(len(p.diffLines)-1, 0))
+	for i, line := range p.diffLines {
+		if lineTarget, ok := line.row.target(); hasTarget && ok && lineTarget == target {
+			p.cursor = i
+			break
+		}
+	}
+}
+
+func (p *PullRequestReview) diffWidth() int {
+	width := p.width/2 - 6
+	if p.cursorMode {
+		// Leave room for the cursor.
+		width--
+	}
+
+	return width
+}
+
+// refreshDiff updates the diff panel after the cursor has moved or the diff
+// has been laid out again, keeping the scroll position.
+func (p *PullRequestReview) refreshDiff() {
+	offset := p.diff.YOffset
+	p.diff = newDiffView(p.diffLines, p.loaded.highlighter, p.width/2-6, p.diff.Height)
+	p.diff.SetYOffset(offset)
+
+	start, end := p.selectionRange()
+	p.diff.SetCursor(p.cursorMode, p.cursor, start, end)
+}
+
+// selectionRange is the range of rendered lines selected, which is just the
+// cursor when not selecting.
+func (p *PullRequestReview) selectionRange() (int, int) {
+	if !p.selecting {
+		return p.cursor, p.cursor
+	}
+
+	return min(p.anchor, p.cursor), max(p.anchor, p.cursor)
+}
+
+// cursorTarget is the line the cursor is on, if any.
+func (p *PullRequestReview) cursorTarget() (services.ReviewComment, bool) {
+	if !p.cursorMode || p.cursor >= len(p.diffLines) {
+		return services.ReviewComment{}, false
 	}
+
+	return p.diffLines[p.cursor].row.target()
+}
+
+// moveCursor moves the cursor to the next diff line in the direction,
+// skipping headers and comments, and scrolls it into view.
+func (p *PullRequestReview) moveCursor(direction int) {
+	for i := p.cursor + direction; i >= 0 && i < len(p.diffLines); i += direction {
+		if _, ok := p.diffLines[i].row.target(); ok {
+			p.cursor = i
+			break
+		}
+	}
+
+	if p.cursor < p.diff.YOffset {
+		p.diff.SetYOffset(p.cursor)
+	}
+	if p.cursor >= p.diff.YOffset+p.diff.Height {
+		p.diff.SetYOffset(p.cursor - p.diff.Height + 1)
+	}
+}
+
+func (p *PullRequestReview) toggleCursorMode() {
+	p.cursorMode = !p.cursorMode
+	p.renderDiff()
+
+	if p.cursorMode {
+		p.focus = focusDiff
+		p.cursor = clamp(p.diff.YOffset, 0, max(len(p.diffLines)-1, 0))
+		if _, ok := p.cursorTarget(); !ok {
+			p.moveCursor(1)
+		}
+	}
+	p.refreshDiff()
+}
+
+// advance moves on to the next pull request in the queue, waiting for it
+// unless it has been prefetched.
+func (p *PullRequestReview) advance() tea.Cmd {
+	if len(p.prefetched) > 0 {
+		next := p.prefetched[0]
+		p.prefetched = p.prefetched[1:]
+
+		return tea.Batch(p.show(next), p.prefetch())
+	}
+	if p.exhausted {
+		return notifications.Notify(notifications.SeverityInfo, "no more pull requests to review")
+	}
+
+	p.reset()
+	p.loading = true
+
+	return tea.Batch(p.spinner.Tick, p.prefetch())
+}
+
+// prefetch fetches another pull request ahead, unless one is on its way or
+// enough have been fetched already.
+func (p *PullRequestReview) prefetch() tea.Cmd {
+	if p.fetching || p.exhausted || len(p.prefetched) >= prefetchCount {
+		return nil
+	}
+	p.fetching = true
+
+	return fetchNext(p.ctx, p.provider, p.renders, p.descriptionWidth())
+}
+
+// fetched shows the pull request when waiting for it, otherwise it is kept
+// for later. Failing to prefetch is only reported, the next skip tries again.
+func (p *PullRequestReview) fetched(msg prFetchedMsg) tea.Cmd {
+	if msg.provider != p.provider {
+		return nil
+	}
+	p.fetching = false
+	waiting := p.loading && p.opening == nil
+	if waiting {
+		p.loading = false
+	}
+
+	switch {
+	case msg.err != nil && waiting:
+		return ShowError("Failed to get the next pull request", msg.err, nextPr)
+	case msg.err != nil:
+		return notifications.Notify(notifications.SeverityWarning, "failed to prefetch the next pull request: %s", msg.err)
+	case !msg.ok:
+		p.exhausted = true
+		if waiting {
+			return notifications.Notify(notifications.SeverityInfo, "no more pull requests to review")
+		}
+
+		return nil
+	case waiting:
+		return tea.Batch(p.show(msg.pr), p.prefetch())
+	default:
+		p.prefetched = append(p.prefetched, msg.pr)
+
+		return p.prefetch()
+	}
+}
+
+func (p *PullRequestReview) show(loaded *loadedPr) tea.Cmd {
+	if err := p.setPr(loaded); err != nil {
+		return ShowError(fmt.Sprintf("Failed to parse the diff of %s", loaded.pr.Ref), err, nil)
+	}
+
+	return nil
+}
+
+// nextPrMsg moves on to the next pull request, it retries getting one.
+type nextPrMsg struct{}
+
+func nextPr() tea.Msg {
+	return nextPrMsg{}
+}
+
+// firstPrMsg gets the head of the queue unless a pull request was opened in
+// the meantime.
+type firstPrMsg struct{}
+
+// openPullRequestMsg goes to the review page showing the pull request.
+type openPullRequestMsg struct {
+	changePage
+	pr *services.PullRequest
+}
+
+// OpenPullRequest reviews the pull request rather than the next one in the
+// queue.
+func OpenPullRequest(pr *services.PullRequest) tea.Cmd {
+	return func() tea.Msg {
+		return &openPullRequestMsg{
+			changePage: changePage{
+				page:       PullRequestReviewPage,
+				navigation: NavigationPush,
+			},
+			pr: pr,
+		}
+	}
+}
+
+// rerenderMsg renders the current pull request again.
+type rerenderMsg struct{}
+
+func (p *PullRequestReview) renderFailed(err error) tea.Cmd {
+	if err == nil {
+		return nil
+	}
+
+	return ShowError(fmt.Sprintf("Failed to render %s", p.currentPr.Ref), err, func() tea.Msg {
+		return rerenderMsg{}
+	})
+}
+
+func (p *PullRequestReview) openModal(kind services.PullRequestActionKind) tea.Cmd {
+	if p.currentPr == nil {
+		return nil
+	}
+
+	p.modal = newReviewModal(p.currentPr.Ref, kind, p.pendingComments, min(p.width/2, 80))
+
+	return p.modal.Init()
+}
+
+func (p *PullRequestReview) openLineCommentModal(suggestion bool) tea.Cmd {
+	start, end := p.selectionRange()
+	comment, lines, err := selectionComment(p.diffLines, start, end)
+	if err == nil && suggestion && len(lines) == 0 {
+		err = errSuggestionOnRemoved
+	}
+	if err != nil {
+		return notifications.Notify(notifications.SeverityWarning, "%s", err)
+	}
+
+	if suggestion {
+		p.modal = newSuggestionModal(p.currentPr.Ref, comment, lines, min(p.width/2, 80))
+	} else {
+		p.modal = newLineCommentModal(p.currentPr.Ref, comment, min(p.width/2, 80))
+	}
+
+	return p.modal.Init()
+}
+
+// queueComment adds the line comment to the pending review and shows it
+// under its line.
+func (p *PullRequestReview) queueComment(comment services.ReviewComment) tea.Cmd {
+	p.pendingComments = append(p.pendingComments, comment)
+	p.selecting = false
+
+	p.renderDiff()
+	p.refreshDiff()
+
+	return notifications.Notify(
+		notifications.SeverityInfo,
+		"%d pending line comment(s), submit them with a review", len(p.pendingComments),
+	)
+}
+
+func (p *PullRequestReview) updateModal(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, p.modal.keyMap.Cancel):
+		p.modal = nil
+		return nil
+	case key.Matches(msg, p.modal.keyMap.Editor):
+		if p.modal.submitting {
+			return nil
+		}
+
+		return openEditor(p.modal.input.Value())
+	case key.Matches(msg, p.modal.keyMap.Submit):
+		if p.modal.submitting {
+			return nil
+		}
+
+		if p.modal.lineComment != nil {
+			comment, err := p.modal.comment()
+			p.modal.err = err
+			if err != nil {
+				return nil
+			}
+
+			p.modal = nil
+			return p.queueComment(comment)
+		}
+
+		action, err := p.modal.action()
+		p.modal.err = err
+		if err != nil {
+			return nil
+		}
+		p.modal.submitting = true
+
+		return p.submit(p.modal.ref, action)
+	}
+
+	return p.modal.Update(msg)
+}
+
+func (p *PullRequestReview) openConfirm(kind services.PullRequestActionKind) {
+	if p.currentPr == nil {
+		return
+	}
+
+	p.confirm = newConfirmModal(p.currentPr, kind)
+}
+
+func (p *PullRequestReview) updateConfirm(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, p.confirm.keyMap.Cancel):
+		p.confirm = nil
+		return nil
+	case key.Matches(msg, p.confirm.keyMap.Confirm):
+		if p.confirm.submitting {
+			return nil
+		}
+
+		action, err := p.confirm.action()
+		p.confirm.err = err
+		if err != nil {
+			return nil
+		}
+		p.confirm.submitting = true
+
+		return p.submit(p.confirm.ref, action)
+	}
+
+	p.confirm.Update(msg)
+
+	return nil
+}
+
+// submit performs the action through the provider outside of the update
+// loop, a panic is reported as the action failing.
+func (p *PullRequestReview) submit(ref services.PullRequestRef, action services.PullRequestAction) tea.Cmd {
+	ctx, provider := p.ctx, p.provider
+
+	return recoverCmd(func() tea.Msg {
+		return actionSubmittedMsg{
+			ref:    ref,
+			action: action,
+			err:    provider.Act(ctx, ref, action),
+		}
+	}, func(err error) tea.Msg {
+		return actionSubmittedMsg{ref: ref, action: action, err: err}
+	})
+}
+
+// actionSubmitted reports the outcome of an action and moves on to the next
+// pull request, failures are left in the modal for another attempt.
+func (p *PullRequestReview) actionSubmitted(msg actionSubmittedMsg) tea.Cmd {
+	if msg.err != nil {
+		switch {
+		case p.modal != nil:
+			p.modal.submitting = false
+			p.modal.err = msg.err
+		case p.confirm != nil:
+			p.confirm.submitting = false
+			p.confirm.err = msg.err
+		}
+
+		return notifications.Notify(notifications.SeverityError, "%s", msg.err)
+	}
+
+	p.modal = nil
+	p.confirm = nil
+
+	outcome := fmt.Sprintf("%s %s", actionOutcome(msg.action.Kind), msg.ref)
+	if msg.action.MergeMethod != "" {
+		outcome += fmt.Sprintf(" (%s)", msg.action.MergeMethod)
+	}
+	if len(msg.action.Comments) > 0 {
+		outcome += fmt.Sprintf(" with %d line comment(s)", len(msg.action.Comments))
+	}
+	cmds := []tea.Cmd{notifications.Notify(notifications.SeveritySuccess, "%s", outcome)}
+
+	if p.currentPr != nil && p.currentPr.Ref == msg.ref {
+		cmds = append(cmds, p.advance())
+	}
+
+	return tea.Batch(cmds...)
+}
+
+// CapturingInput reports whether the page is taking text input.
+func (p *PullRequestReview) CapturingInput() bool {
+	return p.modal != nil || p.confirm != nil
 }
 
 func (p *PullRequestReview) Init() tea.Cmd {
 	if p.currentPr == nil {
-		pr, ok := p.githubPrService.GetNext()
-		if ok {
-			p.currentPr = pr
+		return func() tea.Msg {
+			return firstPrMsg{}
 		}
 	}
 
@@ -97,19 +650,72 @@ func (p *PullRequestReview) Init() tea.Cmd {
 }
 
 func (p *PullRequestReview) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	cmds := make([]tea.Cmd, 0)
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if p.modal != nil {
+			return p, p.updateModal(msg)
+		}
+		if p.confirm != nil {
+			return p, p.updateConfirm(msg)
+		}
+
 		switch {
 		case key.Matches(msg, p.keyMap.Skip):
-			pr, ok := p.githubPrService.GetNext()
-			if ok {
-				p.currentPr = pr
+			return p, p.advance()
+		case key.Matches(msg, p.keyMap.Approve):
+			return p, p.openModal(services.PullRequestActionApprove)
+		case key.Matches(msg, p.keyMap.RequestChanges):
+			return p, p.openModal(services.PullRequestActionRequestChanges)
+		case key.Matches(msg, p.keyMap.Comment):
+			return p, p.openModal(services.PullRequestActionComment)
+		case key.Matches(msg, p.keyMap.Merge):
+			p.openConfirm(services.PullRequestActionMerge)
+
+			return p, nil
+		case key.Matches(msg, p.keyMap.AutoMerge):
+			p.openConfirm(services.PullRequestActionAutoMerge)
+
+			return p, nil
+		case key.Matches(msg, p.keyMap.Close):
+			p.openConfirm(services.PullRequestActionClose)
+
+			return p, nil
+		case key.Matches(msg, p.keyMap.LineCursor):
+			if p.currentPr == nil {
+				return p, nil
 			}
 
+			p.toggleCursorMode()
+
+			return p, nil
+		case p.cursorMode && p.focus == focusDiff && key.Matches(msg, p.keyMap.CursorUp):
+			p.moveCursor(-1)
+			p.refreshDiff()
+
+			return p, nil
+		case p.cursorMode && p.focus == focusDiff && key.Matches(msg, p.keyMap.CursorDown):
+			p.moveCursor(1)
+			p.refreshDiff()
+
+			return p, nil
+		case p.cursorMode && p.focus == focusDiff && key.Matches(msg, p.keyMap.LineComment):
+			return p, p.openLineCommentModal(false)
+		case p.cursorMode && p.focus == focusDiff && key.Matches(msg, p.keyMap.Suggest):
+			return p, p.openLineCommentModal(true)
+		case p.cursorMode && p.focus == focusDiff && key.Matches(msg, p.keyMap.SelectRange):
+			p.selecting = !p.selecting
+			p.anchor = p.cursor
+			p.refreshDiff()
+
 			return p, nil
 		case key.Matches(msg, p.keyMap.TabNext):
 			p.focus += 1
-			p.focus %= 2
+			p.focus %= focusCount
+		case key.Matches(msg, p.keyMap.SideBySide):
+			p.sideBySide = !p.sideBySide
+			p.ready = false
 		case key.Matches(msg, p.keyMap.Help):
 			p.help.ShowAll = !p.help.ShowAll
 
@@ -121,55 +727,156 @@ func (p *PullRequestReview) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		p.SetSize(msg.Width-h, msg.Height-v)
 
 		p.ready = false
-
-	}
-
-	if !p.ready {
-		bytes := bytes.NewBufferString("")
-		err := quick.Highlight(bytes, p.currentPr.Diff, "diff", "terminal16m", "dracula")
-		if err != nil {
-			panic(err)
+	case actionSubmittedMsg:
+		cmds = append(cmds, p.actionSubmitted(msg))
+	case nextPrMsg:
+		cmds = append(cmds, p.advance())
+	case firstPrMsg:
+		if p.currentPr == nil && !p.loading {
+			cmds = append(cmds, p.advance())
+		}
+	case prFetchedMsg:
+		cmds = append(cmds, p.fetched(msg))
+	case spinner.TickMsg:
+		if !p.loading {
+			return p, nil
 		}
-		height := p.getContentHeight()
 
-		p.diff = p.createViewPort(bytes.String(), height/2)
+		var cmd tea.Cmd
+		p.spinner, cmd = p.spinner.Update(msg)
 
-		style := glamour.DefaultStyles["dracula"]
-		style.Document.Margin = func() *uint {
-			var zero uint = 0
-			return &zero
-		}()
-		renderer, err := glamour.NewTermRenderer(glamour.WithStyles(*style), glamour.WithWordWrap(p.width/2-6))
-		if err != nil {
-			panic(err)
+		return p, cmd
+	case *openPullRequestMsg:
+		p.reset()
+		p.loading = true
+		p.opening = msg.pr
+		cmds = append(cmds, p.spinner.Tick, loadOpened(msg.pr, p.renders, p.descriptionWidth()))
+	case prOpenedMsg:
+		if msg.opened != p.opening {
+			return p, nil
+		}
+		p.loading = false
+		if msg.err != nil {
+			p.opening = nil
+			cmds = append(cmds, ShowError(fmt.Sprintf("Failed to open %s", msg.opened.Ref), msg.err, nil))
+		} else {
+			cmds = append(cmds, p.show(msg.pr), p.prefetch())
+		}
+	case renderedMsg:
+		cmds = append(cmds, p.rendered(msg))
+	case QueueSelectedMsg:
+		// The next review starts from the head of the new queue, pull
+		// requests still on their way from the previous one are dropped.
+		p.provider = msg.Queue.Provider
+		p.reset()
+		p.loading = false
+		p.fetching = false
+		p.exhausted = false
+		p.prefetched = nil
+		p.modal = nil
+		p.confirm = nil
+	case rerenderMsg:
+		p.ready = false
+	case editorFinishedMsg:
+		if p.modal != nil {
+			p.modal.err = msg.err
+			if msg.err == nil {
+				p.modal.input.SetValue(msg.content)
+			}
 		}
 
-		description, err := renderer.Render(p.currentPr.Description)
-		if err != nil {
-			panic(err)
+		return p, nil
+	case fileSelectedMsg:
+		if offset, ok := p.fileOffsets[msg.path]; ok {
+			p.diff.SetYOffset(offset)
+			p.focus = focusDiff
 		}
-		p.description = p.createViewPort(description, height)
 
-		p.ready = true
+		return p, nil
 	}
 
-	var (
-		cmd  tea.Cmd
-		cmds = make([]tea.Cmd, 0)
-	)
+	if !p.ready && p.currentPr != nil {
+		cmds = append(cmds, p.render())
+	}
+
+	var cmd tea.Cmd
 
-	if p.focus == 0 {
+	switch p.focus {
+	case focusDescription:
 		p.description, cmd = p.description.Update(msg)
 		cmds = append(cmds, cmd)
-	}
-	if p.focus == 1 {
+	case focusFiles:
+		p.files, cmd = p.files.Update(msg)
+		cmds = append(cmds, cmd)
+	case focusDiff:
 		p.diff, cmd = p.diff.Update(msg)
 		cmds = append(cmds, cmd)
 	}
 
+	if p.modal != nil {
+		cmds = append(cmds, p.modal.Update(msg))
+	}
+
 	return p, tea.Batch(cmds...)
 }
 
+// render lays out the panels of the current pull request outside of the
+// update loop, the panels shown are kept until it is done.
+func (p *PullRequestReview) render() tea.Cmd {
+	p.ready = true
+
+	return renderPr(p.renders, p.loaded, p.renderInputs(), slices.Clone(p.pendingComments))
+}
+
+func (p *PullRequestReview) renderInputs() renderInputs {
+	return renderInputs{
+		diffWidth:        p.diffWidth(),
+		descriptionWidth: p.descriptionWidth(),
+		sideBySide:       p.sideBySide,
+		pendingComments:  len(p.pendingComments),
+	}
+}
+
+// rendered shows the rendered panels, unless the pull request or what it was
+// rendered from changed in the meantime. Parts which failed to render are
+// shown as plain text, and a broken pull request shows the error page rather
+// than taking down the session.
+func (p *PullRequestReview) rendered(msg renderedMsg) (cmd tea.Cmd) {
+	if msg.pr != p.loaded {
+		return nil
+	}
+	if msg.inputs != p.renderInputs() {
+		p.ready = false
+		return nil
+	}
+	if msg.err != nil {
+		return p.renderFailed(msg.err)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			cmd = p.renderFailed(fmt.Errorf("%v", r))
+		}
+	}()
+
+	height := p.getContentHeight()
+
+	p.setDiff(msg.diff)
+	p.diff.Height = max(0, height/2)
+	p.refreshDiff()
+	p.description = p.createViewPort(msg.description, max(0, height-p.getFileTreeHeight()))
+	p.files.SetSize(p.width/2-2, max(0, p.getFileTreeHeight()-2))
+
+	if msg.descriptionErr != nil {
+		return notifications.Notify(notifications.SeverityWarning, "showing the description as plain text: %s", msg.descriptionErr)
+	}
+
+	return nil
+}
+
+func (p *PullRequestReview) descriptionWidth() int {
+	return p.width/2 - 6
+}
+
 func (p *PullRequestReview) createViewPort(input string, height int) viewport.Model {
 	diffStrings := strings.Split(input, "\n")
 	renderedDiffStrings := make([]string, 0, len(diffStrings))
@@ -208,12 +915,74 @@ func borderBox(focus bool) lipgloss.Style {
 }
 
 func (p *PullRequestReview) renderTitle() (string, int) {
-	title := titleBox.Width(p.width-1).Render(p.currentPr.Title) + "\n"
+	pr := p.currentPr
+	title := fmt.Sprintf("%s %s by %s", pr.Ref, pr.Title, pr.Author)
+	if pr.Draft {
+		title = "[draft] " + title
+	}
+	title = titleBox.Width(p.width-1).Render(title) + "\n"
+	if pr.Rank != nil {
+		title += commentHeaderStyle.Copy().Width(p.width-1).Render(pr.Rank.String()) + "\n"
+	}
 	titleHeight := lipgloss.Height(title)
 
 	return title, titleHeight
 }
 
+var (
+	commentHeaderStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#AAAAAA"))
+
+	checkSuccessStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B"))
+	checkFailureStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555"))
+	checkPendingStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#F1FA8C"))
+	checkNeutralStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#AAAAAA"))
+)
+
+// renderComments renders the comments fitting in the given number of lines,
+// noting how many more there are.
+func renderComments(comments []services.Comment, height int) string {
+	rendered := make([]string, 0, len(comments))
+	lines := 0
+	for i, comment := range comments {
+		header := fmt.Sprintf("%s · %s", comment.Author, comment.CreatedAt.Format("2006-01-02 15:04"))
+		if comment.Path != "" {
+			header += fmt.Sprintf(" · %s:%d", comment.Path, comment.Line)
+		}
+
+		// Leave a line for the note on the remaining comments.
+		lines += strings.Count(comment.Body, "\n") + 3
+		if lines > height && i < len(comments)-1 || lines-1 > height {
+			rendered = append(rendered, commentHeaderStyle.Render(fmt.Sprintf("… %d more comments", len(comments)-i)))
+			break
+		}
+
+		rendered = append(rendered, commentHeaderStyle.Render(header)+"\n"+comment.Body)
+	}
+
+	return strings.Join(rendered, "\n\n")
+}
+
+func renderChecks(checks []services.Check) string {
+	rendered := make([]string, 0, len(checks))
+	for _, check := range checks {
+		var line string
+		switch {
+		case check.Pending():
+			line = checkPendingStyle.Render("● " + check.Name)
+		case check.Failed():
+			line = checkFailureStyle.Render("✗ " + check.Name)
+		case check.Conclusion == services.CheckConclusionSuccess:
+			line = checkSuccessStyle.Render("✓ " + check.Name)
+		default:
+			line = checkNeutralStyle.Render("- " + check.Name)
+		}
+
+		rendered = append(rendered, line)
+	}
+
+	return strings.Join(rendered, "\n")
+}
+
 func (p *PullRequestReview) renderHelp() (string, int) {
 	help := p.help.View(p.keyMap)
 	helpHeight := lipgloss.Height(help)
@@ -221,6 +990,12 @@ func (p *PullRequestReview) renderHelp() (string, int) {
 	return help, helpHeight
 }
 
+// getFileTreeHeight is the height of the file tree panel including its
+// border, it takes up to a third of the left column.
+func (p *PullRequestReview) getFileTreeHeight() int {
+	return min(len(p.files.visible)+2, p.getContentHeight()/3)
+}
+
 func (p *PullRequestReview) getContentHeight() int {
 	_, helpHeight := p.renderHelp()
 	_, titleHeight := p.renderTitle()
@@ -236,19 +1011,29 @@ func (p *PullRequestReview) View() string {
 		pr := p.currentPr
 		title, _ := p.renderTitle()
 
-		comments := strings.Join(pr.Comments, "\n\n")
-		statusChecks := strings.Join(pr.StatusChecks, "\n\n")
-		diff := p.diff.View()
-
 		remainingHeight := p.getContentHeight()
 
+		// The comments share the top right with the checks, both in a box.
+		comments := renderComments(pr.Comments, remainingHeight/2-5-len(pr.Checks))
+		statusChecks := renderChecks(pr.Checks)
+		diff := p.diff.View()
+
+		fileTreeHeight := p.getFileTreeHeight()
 		left := lipgloss.PlaceHorizontal(
 			p.width/2, lipgloss.Left,
-			borderBox(p.focus == 0).
-				Copy().
-				Width(p.width/2).
-				Height(remainingHeight-2).
-				Render(p.description.View()),
+			lipgloss.JoinVertical(
+				lipgloss.Top,
+				borderBox(p.focus == focusDescription).
+					Copy().
+					Width(p.width/2).
+					Height(remainingHeight-fileTreeHeight-2).
+					Render(p.description.View()),
+				borderBox(p.focus == focusFiles).
+					Copy().
+					Width(p.width/2).
+					Height(fileTreeHeight-2).
+					Render(p.files.View()),
+			),
 		)
 		rightTop := lipgloss.PlaceVertical(
 			remainingHeight/2-1, lipgloss.Top,
@@ -268,7 +1053,7 @@ func (p *PullRequestReview) View() string {
 
 		rightBottom := lipgloss.PlaceVertical(
 			remainingHeight-lipgloss.Height(rightTop), lipgloss.Top,
-			borderBox(p.focus == 1).
+			borderBox(p.focus == focusDiff).
 				Copy().
 				Width(p.width/2-4).
 				Height(remainingHeight-lipgloss.Height(rightTop)).
@@ -289,17 +1074,12 @@ func (p *PullRequestReview) View() string {
 			title,
 			contentBox.Copy().Height(remainingHeight).Render(content),
 		)
+	} else if p.exhausted && !p.loading {
+		body = "no more pull requests to review"
 	} else {
-		body = "loading..."
+		body = p.spinner.View() + " loading the next pull request..."
 	}
 
-	notificationBox := lipgloss.NewStyle().
-		PaddingLeft(1).
-		PaddingRight(1).
-		Border(lipgloss.NormalBorder()).
-		Width(30).
-		Render("something\nsomething\nsomething")
-
 	content := docStyle.Render(
 		lipgloss.JoinVertical(
 			0,
@@ -307,11 +1087,21 @@ func (p *PullRequestReview) View() string {
 			help,
 		),
 	)
-	content = utility.PlaceOverlay(
-		p.width-30, p.height,
-		notificationBox, content,
-		false,
-	)
+
+	var modal string
+	switch {
+	case p.modal != nil:
+		modal = p.modal.View()
+	case p.confirm != nil:
+		modal = p.confirm.View()
+	}
+	if modal != "" {
+		content = utility.PlaceOverlay(
+			max((p.width-lipgloss.Width(modal))/2, 0), max((p.height-lipgloss.Height(modal))/2, 0),
+			modal, content,
+			false,
+		)
+	}
 
 	return content
 }
@@ -0,0 +1,79 @@
+package pages
+
+import (
+	"context"
+	"fmt"
+	"shuttle-extensions-template/internal/services"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestReviewResizesToTinyTerminals(t *testing.T) {
+	pr, err := services.NewDemoPullRequestProvider().Get(context.Background(), services.PullRequestRef{Repo: "lunarway/demo", Number: 1})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	for _, sideBySide := range []bool{false, true} {
+		for _, size := range [][2]int{{5, 3}, {1, 1}, {0, 0}, {20, 8}} {
+			t.Run(fmt.Sprintf("%dx%d side-by-side %v", size[0], size[1], sideBySide), func(t *testing.T) {
+				p := newReviewAt(t, pr, 120, 40)
+				p.sideBySide = sideBySide
+
+				p.Update(tea.WindowSizeMsg{Width: size[0], Height: size[1]})
+				renderNow(p)
+				_ = p.View()
+
+				if p.diff.Height < 0 {
+					t.Errorf("diff height = %d, want it clamped to 0", p.diff.Height)
+				}
+				p.diff.SetYOffset(10)
+				if p.diff.YOffset < 0 || p.diff.YOffset > len(p.diff.lines) {
+					t.Errorf("diff offset = %d, out of the %d lines", p.diff.YOffset, len(p.diff.lines))
+				}
+				_ = p.View()
+			})
+		}
+	}
+}
+
+// actPanickingProvider panics when acting on a pull request.
+type actPanickingProvider struct {
+	services.PullRequestProvider
+}
+
+func (actPanickingProvider) Act(ctx context.Context, ref services.PullRequestRef, action services.PullRequestAction) error {
+	panic("forge broke")
+}
+
+func TestReviewSubmitRecovers(t *testing.T) {
+	demo := services.NewDemoPullRequestProvider()
+	pr, err := demo.Get(context.Background(), services.PullRequestRef{Repo: "lunarway/demo", Number: 1})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	p := newReviewAt(t, pr, 120, 40)
+	p.provider = actPanickingProvider{demo}
+
+	p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	_, cmd := p.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if cmd == nil {
+		t.Fatal("submitting the review didn't return a command")
+	}
+
+	msg, ok := cmd().(actionSubmittedMsg)
+	if !ok {
+		t.Fatalf("submitting returned %T, want actionSubmittedMsg", msg)
+	}
+	if msg.err == nil || !strings.Contains(msg.err.Error(), "forge broke") {
+		t.Errorf("err = %v, want the panic", msg.err)
+	}
+
+	p.Update(msg)
+	if p.modal == nil || p.modal.submitting || p.modal.err == nil {
+		t.Errorf("the review modal isn't left open with the error for another attempt")
+	}
+}
@@ -1,9 +1,18 @@
 package pages
 
 import (
+	"context"
+	"fmt"
+	"shuttle-extensions-template/internal/notifications"
+	"shuttle-extensions-template/internal/services"
+	"strconv"
+	"strings"
+
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
-	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -15,17 +24,57 @@ var (
 )
 
 type tableKeyMap struct {
-	Begin key.Binding
-	Help  key.Binding
-	Quit  key.Binding
+	Open         key.Binding
+	Begin        key.Binding
+	Filter       key.Binding
+	ApplyFilter  key.Binding
+	CancelFilter key.Binding
+	Sort         key.Binding
+	NextQueue    key.Binding
+	PrevQueue    key.Binding
+	Reload       key.Binding
+	Help         key.Binding
+	Quit         key.Binding
 }
 
 func newTableKeyMap() tableKeyMap {
 	return tableKeyMap{
+		Open: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "review the selected pull request"),
+		),
 		Begin: key.NewBinding(
 			key.WithKeys("b"),
 			key.WithHelp("b", "begin reviewing pull requests"),
 		),
+		Filter: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "filter"),
+		),
+		ApplyFilter: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "apply filter"),
+		),
+		CancelFilter: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "clear filter"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("1", "2", "3", "4", "5", "6", "7"),
+			key.WithHelp("1-7", "sort by column"),
+		),
+		NextQueue: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "next queue"),
+		),
+		PrevQueue: key.NewBinding(
+			key.WithKeys("shift+tab"),
+			key.WithHelp("shift+tab", "previous queue"),
+		),
+		Reload: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "reload"),
+		),
 		Help: key.NewBinding(
 			key.WithKeys("?"),
 			key.WithHelp("?", "toggle help"),
@@ -39,14 +88,20 @@ func newTableKeyMap() tableKeyMap {
 
 func (t tableKeyMap) ShortHelp() []key.Binding {
 	return []key.Binding{
-		t.Begin, t.Help, t.Quit,
+		t.Open, t.Begin, t.Filter, t.Sort, t.NextQueue, t.Help, t.Quit,
 	}
 }
 
 func (t tableKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{
-			t.Begin,
+			t.Open, t.Begin, t.Reload,
+		},
+		{
+			t.NextQueue, t.PrevQueue,
+		},
+		{
+			t.Filter, t.ApplyFilter, t.CancelFilter, t.Sort,
 		},
 		{
 			t.Help, t.Quit,
@@ -54,98 +109,429 @@ func (t tableKeyMap) FullHelp() [][]key.Binding {
 	}
 }
 
-type item struct {
-	title string
+// Queue is a named source of pull requests to review.
+type Queue struct {
+	Name     string
+	Provider services.PullRequestProvider
 }
 
-func (i item) Title() string {
-	return i.title
+// QueueSelectedMsg is sent to every page when switching to another queue.
+type QueueSelectedMsg struct {
+	Queue Queue
 }
 
-func (i item) Description() string {
-	return i.title
+// pullRequestsLoadedMsg is sent when the pull requests have been listed and
+// fetched, failed holds the errors of the pull requests which failed to load.
+// queue is the name of the queue they were loaded from.
+type pullRequestsLoadedMsg struct {
+	queue  string
+	prs    []*services.PullRequest
+	failed []error
+	err    error
 }
 
-func (i item) FilterValue() string {
-	return i.title
-}
+type reloadPullRequestsMsg struct{}
+
+func loadPullRequests(ctx context.Context, queue Queue) tea.Cmd {
+	provider := queue.Provider
 
-var _ list.DefaultItem = &item{}
+	return recoverCmd(func() tea.Msg {
+		refs, err := provider.List(ctx)
+		if err != nil {
+			return pullRequestsLoadedMsg{queue: queue.Name, err: err}
+		}
+
+		prs, errs := services.GetEach(ctx, refs, provider.Get)
+
+		msg := pullRequestsLoadedMsg{queue: queue.Name, prs: make([]*services.PullRequest, 0, len(prs))}
+		for i, pr := range prs {
+			if errs[i] != nil {
+				msg.failed = append(msg.failed, errs[i])
+				continue
+			}
+
+			msg.prs = append(msg.prs, pr)
+		}
+
+		return msg
+	}, func(err error) tea.Msg {
+		return pullRequestsLoadedMsg{queue: queue.Name, err: err}
+	})
+}
 
 type PullRequestTable struct {
-	list   list.Model
-	keyMap tableKeyMap
-	help   help.Model
+	table   table.Model
+	filter  textinput.Model
+	keyMap  tableKeyMap
+	help    help.Model
+	spinner spinner.Model
+
+	ctx     context.Context
+	queues  []Queue
+	queue   int
+	loading bool
+	loaded  bool
+
+	// prs are all the loaded pull requests and visible those matching the
+	// query, in the sorted order shown.
+	prs        []*services.PullRequest
+	visible    []*services.PullRequest
+	query      services.Query
+	queryErr   error
+	filtering  bool
+	sortColumn int
+	descending bool
 
 	width, height int
 }
 
 func (p *PullRequestTable) Init() tea.Cmd {
+	if p.loaded || p.loading {
+		return nil
+	}
+
+	return p.load()
+}
+
+func (p *PullRequestTable) load() tea.Cmd {
+	p.loading = true
+
+	return tea.Batch(p.spinner.Tick, loadPullRequests(p.ctx, p.queues[p.queue]))
+}
+
+// selectQueue switches to the queue at the index, wrapping around, and lets
+// the other pages know.
+func (p *PullRequestTable) selectQueue(index int) tea.Cmd {
+	p.queue = (index + len(p.queues)) % len(p.queues)
+	p.loaded = false
+	p.prs = nil
+	p.refreshRows()
+
+	queue := p.queues[p.queue]
+
+	return tea.Batch(p.load(), func() tea.Msg {
+		return QueueSelectedMsg{Queue: queue}
+	})
+}
+
+func (p *PullRequestTable) setPullRequests(msg pullRequestsLoadedMsg) tea.Cmd {
+	if msg.queue != p.queues[p.queue].Name {
+		// Loaded before switching queues.
+		return nil
+	}
+
+	p.loading = false
+	if msg.err != nil {
+		return ShowError("Failed to list pull requests", msg.err, func() tea.Msg {
+			return reloadPullRequestsMsg{}
+		})
+	}
+	p.loaded = true
+	p.prs = msg.prs
+	p.refreshRows()
+
+	if len(msg.failed) > 0 {
+		return notifications.Notify(
+			notifications.SeverityWarning,
+			"failed to load %d pull request(s): %s", len(msg.failed), msg.failed[0],
+		)
+	}
+
 	return nil
 }
 
+// refreshRows filters and sorts the pull requests into the rows of the table,
+// keeping the selected pull request selected when it is still shown.
+func (p *PullRequestTable) refreshRows() {
+	selected := p.selected()
+
+	p.visible = make([]*services.PullRequest, 0, len(p.prs))
+	for _, pr := range p.prs {
+		if p.query.Matches(pr) {
+			p.visible = append(p.visible, pr)
+		}
+	}
+	sortPullRequests(p.visible, p.sortColumn, p.descending)
+
+	rows := make([]table.Row, 0, len(p.visible))
+	cursor := 0
+	for i, pr := range p.visible {
+		if selected != nil && pr.Ref == selected.Ref {
+			cursor = i
+		}
+
+		row := make(table.Row, 0, len(tableColumns))
+		for _, column := range tableColumns {
+			row = append(row, column.value(pr))
+		}
+		rows = append(rows, row)
+	}
+
+	p.table.SetColumns(p.columns())
+	p.table.SetRows(rows)
+	p.table.SetCursor(cursor)
+}
+
+// columns sizes the columns to the page, marking the column sorted on.
+func (p *PullRequestTable) columns() []table.Column {
+	// Cells are padded by a space on either side.
+	remaining := p.width - 2*len(tableColumns)
+	for i, column := range tableColumns {
+		if i != titleColumn {
+			remaining -= column.width
+		}
+	}
+
+	columns := make([]table.Column, 0, len(tableColumns))
+	for i, column := range tableColumns {
+		title, width := column.title, column.width
+		if i == titleColumn {
+			width = max(remaining, column.width)
+		}
+		if i == p.sortColumn {
+			title += " ▲"
+			if p.descending {
+				title = column.title + " ▼"
+			}
+		}
+
+		columns = append(columns, table.Column{Title: title, Width: width})
+	}
+
+	return columns
+}
+
+func (p *PullRequestTable) selected() *services.PullRequest {
+	cursor := p.table.Cursor()
+	if cursor < 0 || cursor >= len(p.visible) {
+		return nil
+	}
+
+	return p.visible[cursor]
+}
+
+// sortBy sorts on the column, sorting on it again reverses the order.
+func (p *PullRequestTable) sortBy(column int) {
+	if column == p.sortColumn {
+		p.descending = !p.descending
+	} else {
+		p.sortColumn = column
+		p.descending = false
+	}
+
+	p.refreshRows()
+}
+
+func (p *PullRequestTable) setQuery(value string) {
+	query, err := services.ParseQuery(value)
+	p.queryErr = err
+	if err != nil {
+		return
+	}
+
+	p.query = query
+	p.refreshRows()
+}
+
+func (p *PullRequestTable) updateFilter(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, p.keyMap.ApplyFilter):
+		p.filtering = false
+		p.filter.Blur()
+
+		return nil
+	case key.Matches(msg, p.keyMap.CancelFilter):
+		p.filtering = false
+		p.filter.Blur()
+		p.filter.SetValue("")
+		p.setQuery("")
+
+		return nil
+	}
+
+	var cmd tea.Cmd
+	p.filter, cmd = p.filter.Update(msg)
+	p.setQuery(p.filter.Value())
+
+	return cmd
+}
+
+// CapturingInput reports whether the query is being edited.
+func (p *PullRequestTable) CapturingInput() bool {
+	return p.filtering
+}
+
 func (p *PullRequestTable) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if p.filtering {
+			return p, p.updateFilter(msg)
+		}
+
 		switch {
+		case key.Matches(msg, p.keyMap.Open):
+			if selected := p.selected(); selected != nil && !p.loading {
+				return p, OpenPullRequest(selected)
+			}
+
+			return p, nil
 		case key.Matches(msg, p.keyMap.Begin):
-			return p, NewChangePage(PullRequestReviewPage)
+			return p, NewPushPage(PullRequestReviewPage)
+		case key.Matches(msg, p.keyMap.Filter):
+			p.filtering = true
+
+			return p, p.filter.Focus()
+		case key.Matches(msg, p.keyMap.Sort):
+			column, err := strconv.Atoi(msg.String())
+			if err == nil && column >= 1 && column <= len(tableColumns) {
+				p.sortBy(column - 1)
+			}
+
+			return p, nil
+		case key.Matches(msg, p.keyMap.NextQueue) && len(p.queues) > 1:
+			return p, p.selectQueue(p.queue + 1)
+		case key.Matches(msg, p.keyMap.PrevQueue) && len(p.queues) > 1:
+			return p, p.selectQueue(p.queue - 1)
+		case key.Matches(msg, p.keyMap.Reload):
+			if p.loading {
+				return p, nil
+			}
+
+			return p, p.load()
 		case key.Matches(msg, p.keyMap.Help):
 			p.help.ShowAll = !p.help.ShowAll
+			p.resize()
 		case key.Matches(msg, p.keyMap.Quit):
 			return p, tea.Quit
-
 		}
 	case tea.WindowSizeMsg:
 		h, v := docStyle.GetFrameSize()
-		p.list.SetSize(msg.Width-h, msg.Height-v)
+		p.SetSize(msg.Width-h, msg.Height-v)
+	case pullRequestsLoadedMsg:
+		return p, p.setPullRequests(msg)
+	case reloadPullRequestsMsg:
+		return p, p.load()
+	case spinner.TickMsg:
+		if !p.loading {
+			return p, nil
+		}
+
+		var cmd tea.Cmd
+		p.spinner, cmd = p.spinner.Update(msg)
+		return p, cmd
 	}
 
 	var cmd tea.Cmd
-	p.list, cmd = p.list.Update(msg)
+	p.table, cmd = p.table.Update(msg)
 	return p, cmd
 }
 
+var (
+	tableTitleStyle = lipgloss.NewStyle().
+			Background(lipgloss.Color("62")).
+			Foreground(lipgloss.Color("230")).
+			Padding(0, 1)
+	tableCountStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4"))
+)
+
+// renderTitle names the queue shown, and the others to switch between.
+func (p *PullRequestTable) renderTitle() string {
+	if len(p.queues) == 1 {
+		return tableTitleStyle.Render("Pending Pull Requests · " + p.queues[0].Name)
+	}
+
+	names := make([]string, 0, len(p.queues))
+	for i, queue := range p.queues {
+		if i == p.queue {
+			names = append(names, tableTitleStyle.Render(queue.Name))
+			continue
+		}
+		names = append(names, tableCountStyle.Render(" "+queue.Name+" "))
+	}
+
+	return "Pending Pull Requests " + strings.Join(names, " ")
+}
+
+func (p *PullRequestTable) renderHeader() string {
+	header := p.renderTitle()
+	if p.loaded {
+		header += tableCountStyle.Render(fmt.Sprintf("  %d of %d", len(p.visible), len(p.prs)))
+	}
+
+	filter := p.filter.View()
+	if !p.filtering && p.filter.Value() == "" {
+		filter = modalHintStyle.Render("/ to filter, such as repo:foo author:bar label:deps is:draft")
+	}
+	if p.queryErr != nil {
+		filter += "  " + modalErrorStyle.Render(p.queryErr.Error())
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, "", filter, "")
+}
+
+// resize fits the table between the header and the help.
+func (p *PullRequestTable) resize() {
+	height := p.height - lipgloss.Height(p.renderHeader()) - lipgloss.Height(p.help.View(p.keyMap))
+	// The table header takes a line.
+	p.table.SetHeight(max(height-1, 1))
+	p.table.SetWidth(p.width)
+	p.table.SetColumns(p.columns())
+}
+
 func (p *PullRequestTable) View() string {
 	help := p.help.View(p.keyMap)
-	helpHeight := lipgloss.Height(help)
 
-	listHeight := p.list.Height()
-	p.list.SetHeight(listHeight - helpHeight)
-	list := p.list.View()
-	p.list.SetHeight(listHeight)
+	if p.loading {
+		return docStyle.Render(
+			lipgloss.JoinVertical(
+				0,
+				p.renderTitle(),
+				"",
+				p.spinner.View()+" loading pull requests...",
+				help,
+			),
+		)
+	}
 
 	return docStyle.Render(
-		lipgloss.JoinVertical(0, list, help),
+		lipgloss.JoinVertical(0, p.renderHeader(), p.table.View(), help),
 	)
 }
 
-func NewPullRequestTable() *PullRequestTable {
-	list := list.New([]list.Item{
-		item{
-			title: "something",
-		},
-		item{
-			title: "something 123",
-		},
-		item{
-			title: "something 456",
-		},
-	}, list.NewDefaultDelegate(), 0, 0)
-	list.Title = "Pending Pull Requests"
+func newTableKeys() table.KeyMap {
+	keys := table.DefaultKeyMap()
+	// b begins reviewing.
+	keys.PageUp.SetKeys("pgup")
+	keys.PageDown.SetKeys("pgdown")
 
-	list.SetFilteringEnabled(false)
-	list.SetShowStatusBar(false)
-	list.SetShowHelp(false)
+	return keys
+}
+
+// NewPullRequestTable lists the pull requests of the queue at the index,
+// there must be at least one queue.
+func NewPullRequestTable(ctx context.Context, queues []Queue, queue int) *PullRequestTable {
+	filter := textinput.New()
+	filter.Prompt = "/ "
+	filter.Placeholder = "repo:foo author:bar label:deps is:draft"
 
 	return &PullRequestTable{
-		list:   list,
-		keyMap: newTableKeyMap(),
-		help:   help.New(),
+		table: table.New(
+			table.WithFocused(true),
+			table.WithKeyMap(newTableKeys()),
+		),
+		filter:  filter,
+		keyMap:  newTableKeyMap(),
+		help:    help.New(),
+		spinner: spinner.New(spinner.WithSpinner(spinner.Dot)),
+
+		ctx:    ctx,
+		queues: queues,
+		queue:  queue,
 	}
 }
 
 func (p *PullRequestTable) SetSize(width, height int) {
 	p.width = width
 	p.height = height
+	p.resize()
 }
@@ -0,0 +1,51 @@
+package pages
+
+import (
+	"context"
+	"shuttle-extensions-template/internal/services"
+	"strings"
+	"testing"
+)
+
+// listPanickingProvider panics when listing the pull requests.
+type listPanickingProvider struct {
+	services.PullRequestProvider
+}
+
+func (listPanickingProvider) List(ctx context.Context) ([]services.PullRequestRef, error) {
+	panic("listing broke")
+}
+
+// getPanickingProvider panics when getting the first demo pull request.
+type getPanickingProvider struct {
+	services.PullRequestProvider
+}
+
+func (g getPanickingProvider) Get(ctx context.Context, ref services.PullRequestRef) (*services.PullRequest, error) {
+	if ref.Number == 1 {
+		panic("getting broke")
+	}
+
+	return g.PullRequestProvider.Get(ctx, ref)
+}
+
+func TestLoadPullRequestsRecovers(t *testing.T) {
+	demo := services.NewDemoPullRequestProvider()
+	refs, err := demo.List(context.Background())
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	msg := loadPullRequests(context.Background(), Queue{Name: "broken", Provider: listPanickingProvider{demo}})().(pullRequestsLoadedMsg)
+	if msg.queue != "broken" || msg.err == nil || !strings.Contains(msg.err.Error(), "listing broke") {
+		t.Errorf("loadPullRequests() = %+v, want the panic listing", msg)
+	}
+
+	msg = loadPullRequests(context.Background(), Queue{Name: "partly broken", Provider: getPanickingProvider{demo}})().(pullRequestsLoadedMsg)
+	if msg.err != nil {
+		t.Fatalf("loadPullRequests() error = %v", msg.err)
+	}
+	if len(msg.prs) != len(refs)-1 || len(msg.failed) != 1 || !strings.Contains(msg.failed[0].Error(), "getting broke") {
+		t.Errorf("loadPullRequests() = %d pull requests, failed %v, want all but the panicking one", len(msg.prs), msg.failed)
+	}
+}
@@ -0,0 +1,187 @@
+package pages
+
+import (
+	"fmt"
+	"hash/fnv"
+	"shuttle-extensions-template/internal/services"
+	"sync"
+
+	"github.com/charmbracelet/glamour"
+)
+
+// maxRenderCacheEntries bounds the renders kept, enough for the pull request
+// being reviewed and the ones prefetched at a few widths each.
+const maxRenderCacheEntries = 64
+
+// maxMarkdownRenderers bounds the markdown renderers kept, one per width, so
+// dragging the edge of the terminal doesn't keep one for every width passed.
+const maxMarkdownRenderers = 4
+
+type renderKind int
+
+const (
+	renderUnifiedDiff renderKind = iota
+	renderSideBySideDiff
+	renderDescription
+)
+
+// renderKey identifies a render by the pull request, the hash of the content
+// rendered and the width it was rendered for. Renders which don't depend on
+// the width use 0.
+type renderKey struct {
+	ref   services.PullRequestRef
+	kind  renderKind
+	hash  uint64
+	width int
+}
+
+// renderedContent is a rendered description, or a diff laid out with the
+// line each file starts on.
+type renderedContent struct {
+	text    string
+	lines   []diffLine
+	offsets map[string]int
+	err     error
+}
+
+// renderCache keeps the renders of the pull requests, so resizing and
+// toggling panels only lays out the content again. It is safe to use outside
+// of the update loop.
+type renderCache struct {
+	mu      sync.Mutex
+	entries map[renderKey]renderedContent
+	// order is the order the entries were added in, the oldest are evicted
+	// first.
+	order []renderKey
+
+	// markdown holds a renderer per word wrap width, glamour renderers can't
+	// be used concurrently so each is guarded by its own lock. The widths are
+	// in the order the renderers were created in, the oldest are evicted
+	// first.
+	markdownMu     sync.Mutex
+	markdown       map[int]*markdownRenderer
+	markdownWidths []int
+}
+
+type markdownRenderer struct {
+	mu       sync.Mutex
+	renderer *glamour.TermRenderer
+	err      error
+}
+
+func newRenderCache() *renderCache {
+	return &renderCache{
+		entries:  make(map[renderKey]renderedContent),
+		markdown: make(map[int]*markdownRenderer),
+	}
+}
+
+func hashContent(content string) uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(content))
+
+	return hash.Sum64()
+}
+
+func (c *renderCache) get(key renderKey, render func() renderedContent) renderedContent {
+	c.mu.Lock()
+	content, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok {
+		return content
+	}
+
+	content = render()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok {
+		c.entries[key] = content
+		c.order = append(c.order, key)
+	}
+	for len(c.order) > maxRenderCacheEntries {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+
+	return content
+}
+
+// diff lays out the diff of the pull request, side-by-side when asked to and
+// the width fits it. The lines of the diff are rendered once in view.
+func (c *renderCache) diff(loaded *loadedPr, width int, sideBySide bool) renderedContent {
+	if sideBySide && canRenderSideBySide(width) {
+		key := renderKey{ref: loaded.pr.Ref, kind: renderSideBySideDiff, hash: loaded.diffHash, width: width}
+
+		return c.get(key, func() renderedContent {
+			lines, offsets := renderSideBySide(loaded.files, width)
+
+			return renderedContent{lines: lines, offsets: offsets}
+		})
+	}
+
+	key := renderKey{ref: loaded.pr.Ref, kind: renderUnifiedDiff, hash: loaded.diffHash}
+
+	return c.get(key, func() renderedContent {
+		offsets, lines := unifiedDiffLines(loaded.pr.Diff, loaded.files)
+
+		return renderedContent{lines: lines, offsets: offsets}
+	})
+}
+
+// description renders the markdown description, falling back to the raw
+// markdown.
+func (c *renderCache) description(loaded *loadedPr, width int) (string, error) {
+	if width <= 0 {
+		return loaded.pr.Description, nil
+	}
+
+	key := renderKey{ref: loaded.pr.Ref, kind: renderDescription, hash: loaded.descriptionHash, width: width}
+	content := c.get(key, func() renderedContent {
+		text, err := c.renderMarkdown(loaded.pr.Description, width)
+
+		return renderedContent{text: text, err: err}
+	})
+
+	return content.text, content.err
+}
+
+func (c *renderCache) renderMarkdown(markdown string, width int) (string, error) {
+	c.markdownMu.Lock()
+	renderer, ok := c.markdown[width]
+	if !ok {
+		renderer = &markdownRenderer{}
+		renderer.renderer, renderer.err = newMarkdownRenderer(width)
+		c.markdown[width] = renderer
+		c.markdownWidths = append(c.markdownWidths, width)
+	}
+	for len(c.markdownWidths) > maxMarkdownRenderers {
+		delete(c.markdown, c.markdownWidths[0])
+		c.markdownWidths = c.markdownWidths[1:]
+	}
+	c.markdownMu.Unlock()
+
+	if renderer.err != nil {
+		return markdown, fmt.Errorf("creating the markdown renderer: %w", renderer.err)
+	}
+
+	renderer.mu.Lock()
+	defer renderer.mu.Unlock()
+
+	rendered, err := renderer.renderer.Render(markdown)
+	if err != nil {
+		return markdown, fmt.Errorf("rendering the description: %w", err)
+	}
+
+	return rendered, nil
+}
+
+func newMarkdownRenderer(width int) (*glamour.TermRenderer, error) {
+	style := glamour.DefaultStyles["dracula"]
+	style.Document.Margin = func() *uint {
+		var zero uint = 0
+		return &zero
+	}()
+
+	return glamour.NewTermRenderer(glamour.WithStyles(*style), glamour.WithWordWrap(width))
+}
@@ -0,0 +1,250 @@
+package pages
+
+import (
+	"context"
+	"fmt"
+	"shuttle-extensions-template/internal/diff"
+	"shuttle-extensions-template/internal/services"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// frameBudget is the time a resize may take to lay out the review again
+// without dropping a frame at 60fps.
+const frameBudget = time.Second / 60
+
+// generateDiff generates a diff of Go files of about the given number of
+// lines, each hunk replacing a few lines between unchanged ones.
+func generateDiff(lines int) string {
+	var b strings.Builder
+
+	written := 0
+	for file := 0; written < lines; file++ {
+		fmt.Fprintf(&b, "diff --git a/pkg/file%03d.go b/pkg/file%03d.go\n", file, file)
+		fmt.Fprintf(&b, "index 1111111..2222222 100644\n--- a/pkg/file%03d.go\n+++ b/pkg/file%03d.go\n", file, file)
+		written += 4
+
+		for hunk := 0; hunk < 10 && written < lines; hunk++ {
+			start := hunk*40 + 1
+			fmt.Fprintf(&b, "@@ -%d,10 +%d,10 @@ func handler%d() {\n", start, start, hunk)
+			for i := range 3 {
+				fmt.Fprintf(&b, " \tvalue%d := compute(%d, \"unchanged\")\n", i, start+i)
+			}
+			for i := range 4 {
+				fmt.Fprintf(&b, "-\tresult%d := legacy(value%d, %d) // removed\n", i, i%3, start+3+i)
+			}
+			for i := range 4 {
+				fmt.Fprintf(&b, "+\tresult%d, err := compute(value%d, %d) // added\n", i, i%3, start+3+i)
+			}
+			for i := range 3 {
+				fmt.Fprintf(&b, " \tlog.Printf(\"step %%d\", %d)\n", start+7+i)
+			}
+			written += 15
+		}
+	}
+
+	return b.String()
+}
+
+// benchmarkPr is a pull request with a generated 10k line diff, with a review
+// comment every hundred lines.
+func benchmarkPr(tb testing.TB) *services.PullRequest {
+	tb.Helper()
+
+	unified := generateDiff(10_000)
+	files, err := diff.Parse(unified)
+	if err != nil {
+		tb.Fatalf("parsing the generated diff: %v", err)
+	}
+
+	comments := make([]services.Comment, 0)
+	for _, file := range files {
+		for _, hunk := range file.Hunks {
+			comments = append(comments, services.Comment{
+				Author: "bob",
+				Body:   "Why is this needed?",
+				Path:   file.Path(),
+				Side:   services.DiffSideNew,
+				Line:   hunk.NewStart + 3,
+			})
+		}
+	}
+
+	return &services.PullRequest{
+		Ref:         services.PullRequestRef{Repo: "lunarway/dr", Number: 1},
+		Title:       "Replace the legacy computations",
+		Description: "Replaces `legacy` with `compute` everywhere.\n\n- handles errors\n- logs each step",
+		Author:      "alice",
+		Comments:    comments,
+		Files:       files,
+		Diff:        unified,
+	}
+}
+
+// newReviewAt shows the pull request on the review page at the given size,
+// rendered and viewed as it would be once on screen.
+func newReviewAt(tb testing.TB, pr *services.PullRequest, width, height int) *PullRequestReview {
+	tb.Helper()
+
+	p := NewPullRequestReview(context.Background(), services.NewDemoPullRequestProvider())
+	p.show(loadPr(p.renders, pr, width/2-6))
+	p.Update(tea.WindowSizeMsg{Width: width, Height: height})
+	renderNow(p)
+	_ = p.View()
+
+	return p
+}
+
+// renderNow renders the review page in place of the render command.
+func renderNow(p *PullRequestReview) {
+	msg := p.render()().(renderedMsg)
+	p.rendered(msg)
+}
+
+func TestGenerateDiff(t *testing.T) {
+	pr := benchmarkPr(t)
+
+	if lines := strings.Count(pr.Diff, "\n"); lines < 10_000 || lines > 10_020 {
+		t.Errorf("generated %d lines, want about 10000", lines)
+	}
+}
+
+func TestRenderCacheBoundsMarkdownRenderers(t *testing.T) {
+	pr := benchmarkPr(t)
+	renders := newRenderCache()
+	loaded := loadPr(renders, pr, 80)
+
+	for width := 40; width < 100; width++ {
+		if _, err := renders.description(loaded, width); err != nil {
+			t.Fatalf("description() error = %v", err)
+		}
+	}
+
+	if len(renders.markdown) > maxMarkdownRenderers || len(renders.markdownWidths) != len(renders.markdown) {
+		t.Errorf("kept %d renderers for %d widths, want at most %d", len(renders.markdown), len(renders.markdownWidths), maxMarkdownRenderers)
+	}
+	if len(renders.entries) > maxRenderCacheEntries {
+		t.Errorf("kept %d renders, want at most %d", len(renders.entries), maxRenderCacheEntries)
+	}
+	if _, ok := renders.markdown[99]; !ok {
+		t.Error("the renderer for the latest width was evicted")
+	}
+}
+
+// resizeWidth is the terminal width for the i-th step of dragging the window
+// edge back and forth, the renders of each width are cached after the first
+// pass.
+func resizeWidth(i int) int {
+	return 230 + i%20
+}
+
+// BenchmarkResize measures the update loop handling a resize, rendering the
+// panels runs outside of it and is measured by BenchmarkRender.
+func BenchmarkResize(b *testing.B) {
+	pr := benchmarkPr(b)
+
+	for _, sideBySide := range []bool{false, true} {
+		name := "unified"
+		if sideBySide {
+			name = "side-by-side"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			p := newReviewAt(b, pr, 240, 60)
+			p.sideBySide = sideBySide
+			renderNow(p)
+			_ = p.View()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				p.Update(tea.WindowSizeMsg{Width: resizeWidth(i), Height: 60})
+
+				b.StopTimer()
+				msg := p.render()().(renderedMsg)
+				b.StartTimer()
+
+				p.rendered(msg)
+				_ = p.View()
+			}
+
+			perResize := b.Elapsed() / time.Duration(b.N)
+			b.ReportMetric(float64(perResize)/float64(frameBudget), "frames/op")
+			if perResize > frameBudget {
+				b.Errorf("resizing took %s, over the frame budget of %s", perResize, frameBudget)
+			}
+		})
+	}
+}
+
+// BenchmarkRender measures rendering the panels after a resize, outside of
+// the update loop.
+func BenchmarkRender(b *testing.B) {
+	pr := benchmarkPr(b)
+
+	for _, sideBySide := range []bool{false, true} {
+		name := "unified"
+		if sideBySide {
+			name = "side-by-side"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			p := newReviewAt(b, pr, 240, 60)
+			p.sideBySide = sideBySide
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				p.SetSize(resizeWidth(i), 60)
+				_ = p.render()()
+			}
+		})
+	}
+}
+
+func BenchmarkLayoutDiff(b *testing.B) {
+	pr := benchmarkPr(b)
+	renders := newRenderCache()
+	loaded := loadPr(renders, pr, 80)
+	content := renders.diff(loaded, 0, false)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		layoutDiff(content.lines, content.offsets, pr.Comments, nil, 100)
+	}
+}
+
+func BenchmarkDiffViewView(b *testing.B) {
+	pr := benchmarkPr(b)
+	renders := newRenderCache()
+	loaded := loadPr(renders, pr, 80)
+	layout := layoutPrDiff(renders, loaded, 100, false, nil)
+	view := newDiffView(layout.lines, loaded.highlighter, 100, 50)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Scroll through the diff a page at a time, highlighting the hunks
+		// as they come into view.
+		view.SetYOffset(i * view.Height % len(layout.lines))
+		_ = view.View()
+	}
+}
+
+func TestRenderCommentsFitsHeight(t *testing.T) {
+	comments := benchmarkPr(t).Comments
+
+	for _, height := range []int{0, 1, 10, 25} {
+		rendered := renderComments(comments, height)
+		if lines := strings.Count(rendered, "\n") + 1; lines > max(height, 1) {
+			t.Errorf("renderComments(%d) rendered %d lines", height, lines)
+		}
+		if !strings.Contains(rendered, "more comments") {
+			t.Errorf("renderComments(%d) doesn't note the remaining comments", height)
+		}
+	}
+
+	if rendered := renderComments(comments[:2], 6); strings.Contains(rendered, "more comments") {
+		t.Errorf("renderComments() = %q, want both comments", rendered)
+	}
+}
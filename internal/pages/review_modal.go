@@ -0,0 +1,251 @@
+package pages
+
+import (
+	"errors"
+	"fmt"
+	"shuttle-extensions-template/internal/services"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type reviewModalKeyMap struct {
+	Submit key.Binding
+	Editor key.Binding
+	Cancel key.Binding
+}
+
+func newReviewModalKeyMap() reviewModalKeyMap {
+	return reviewModalKeyMap{
+		Submit: key.NewBinding(
+			key.WithKeys("ctrl+s"),
+			key.WithHelp("ctrl+s", "submit"),
+		),
+		Editor: key.NewBinding(
+			key.WithKeys("ctrl+e"),
+			key.WithHelp("ctrl+e", "edit in $EDITOR"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel"),
+		),
+	}
+}
+
+// actionSubmittedMsg is sent when the provider has handled a review or other
+// action, err is set if it failed.
+type actionSubmittedMsg struct {
+	ref    services.PullRequestRef
+	action services.PullRequestAction
+	err    error
+}
+
+var errReviewBodyRequired = errors.New("a comment is required")
+
+// reviewModal asks for the body of a review before it is submitted, or of a
+// line comment before it is added to the pending review.
+type reviewModal struct {
+	keyMap reviewModalKeyMap
+	ref    services.PullRequestRef
+	kind   services.PullRequestActionKind
+	input  textarea.Model
+	// comments are the pending line comments submitted with the review.
+	comments []services.ReviewComment
+	// lineComment is the line being commented on, it is nil for reviews.
+	lineComment *services.ReviewComment
+	suggestion  bool
+	// tabs is set when the suggested lines are indented with tabs, which the
+	// input replaces with spaces.
+	tabs bool
+
+	submitting bool
+	err        error
+}
+
+func newReviewModal(
+	ref services.PullRequestRef,
+	kind services.PullRequestActionKind,
+	comments []services.ReviewComment,
+	width int,
+) *reviewModal {
+	return &reviewModal{
+		keyMap:   newReviewModalKeyMap(),
+		ref:      ref,
+		kind:     kind,
+		input:    newModalInput(width),
+		comments: comments,
+	}
+}
+
+func newLineCommentModal(ref services.PullRequestRef, comment services.ReviewComment, width int) *reviewModal {
+	return &reviewModal{
+		keyMap:      newReviewModalKeyMap(),
+		ref:         ref,
+		input:       newModalInput(width),
+		lineComment: &comment,
+	}
+}
+
+// newSuggestionModal prepares a suggested change to the lines, pre-filled
+// with their current content.
+func newSuggestionModal(ref services.PullRequestRef, comment services.ReviewComment, lines []string, width int) *reviewModal {
+	modal := newLineCommentModal(ref, comment, width)
+	modal.suggestion = true
+	for _, line := range lines {
+		modal.tabs = modal.tabs || strings.HasPrefix(line, "\t")
+	}
+	modal.input.SetHeight(clamp(len(lines)+3, 6, 15))
+	modal.input.SetValue("```suggestion\n" + strings.Join(lines, "\n") + "\n```")
+
+	return modal
+}
+
+func newModalInput(width int) textarea.Model {
+	input := textarea.New()
+	input.Placeholder = "Leave a comment"
+	input.ShowLineNumbers = false
+	input.SetWidth(width)
+	input.SetHeight(6)
+
+	return input
+}
+
+func (m *reviewModal) Init() tea.Cmd {
+	return m.input.Focus()
+}
+
+// action returns the review to submit. Approvals, and comments made up of
+// only line comments, may be submitted without a body.
+func (m *reviewModal) action() (services.PullRequestAction, error) {
+	body := strings.TrimSpace(m.input.Value())
+	switch {
+	case body != "", m.kind == services.PullRequestActionApprove:
+	case m.kind == services.PullRequestActionComment && len(m.comments) > 0:
+	default:
+		return services.PullRequestAction{}, errReviewBodyRequired
+	}
+
+	return services.PullRequestAction{Kind: m.kind, Body: body, Comments: m.comments}, nil
+}
+
+func (m *reviewModal) comment() (services.ReviewComment, error) {
+	body := strings.TrimSpace(m.input.Value())
+	if body == "" {
+		return services.ReviewComment{}, errReviewBodyRequired
+	}
+
+	if m.tabs {
+		body = indentWithTabs(body)
+	}
+
+	comment := *m.lineComment
+	comment.Body = body
+
+	return comment, nil
+}
+
+func indentWithTabs(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+		lines[i] = strings.Repeat("\t", indent/4) + strings.Repeat(" ", indent%4) + trimmed
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (m *reviewModal) Update(msg tea.Msg) tea.Cmd {
+	if m.submitting {
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+
+	return cmd
+}
+
+var (
+	modalStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("#BD93F9")).
+			Padding(0, 1)
+	modalTitleStyle = lipgloss.NewStyle().Bold(true)
+	modalHintStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4"))
+	modalErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555"))
+)
+
+func reviewActionTitle(kind services.PullRequestActionKind) string {
+	switch kind {
+	case services.PullRequestActionApprove:
+		return "Approve"
+	case services.PullRequestActionRequestChanges:
+		return "Request changes"
+	case services.PullRequestActionMerge:
+		return "Merge"
+	case services.PullRequestActionAutoMerge:
+		return "Enable auto-merge for"
+	case services.PullRequestActionClose:
+		return "Close"
+	default:
+		return "Comment"
+	}
+}
+
+// actionOutcome describes a submitted action, such as "merged".
+func actionOutcome(kind services.PullRequestActionKind) string {
+	switch kind {
+	case services.PullRequestActionApprove:
+		return "approved"
+	case services.PullRequestActionRequestChanges:
+		return "requested changes on"
+	case services.PullRequestActionMerge:
+		return "merged"
+	case services.PullRequestActionAutoMerge:
+		return "enabled auto-merge for"
+	case services.PullRequestActionClose:
+		return "closed"
+	default:
+		return "commented on"
+	}
+}
+
+func (m *reviewModal) View() string {
+	title := fmt.Sprintf("%s %s", reviewActionTitle(m.kind), m.ref)
+	if comment := m.lineComment; comment != nil {
+		lines := strconv.Itoa(comment.Line)
+		if comment.StartLine > 0 && comment.StartLine < comment.Line {
+			lines = fmt.Sprintf("%d-%d", comment.StartLine, comment.Line)
+		}
+
+		title = fmt.Sprintf("Comment on %s:%s", comment.Path, lines)
+		if m.suggestion {
+			title = fmt.Sprintf("Suggest a change to %s:%s", comment.Path, lines)
+		}
+	}
+	lines := []string{modalTitleStyle.Render(title)}
+	if len(m.comments) > 0 {
+		lines = append(lines, modalHintStyle.Render(fmt.Sprintf("with %d line comment(s)", len(m.comments))))
+	}
+	lines = append(lines, m.input.View())
+
+	switch {
+	case m.submitting:
+		lines = append(lines, modalHintStyle.Render("submitting..."))
+	case m.err != nil:
+		lines = append(lines, modalErrorStyle.Render(m.err.Error()))
+	}
+
+	hints := make([]string, 0, 3)
+	for _, binding := range []key.Binding{m.keyMap.Submit, m.keyMap.Editor, m.keyMap.Cancel} {
+		hints = append(hints, fmt.Sprintf("%s %s", binding.Help().Key, binding.Help().Desc))
+	}
+	lines = append(lines, modalHintStyle.Render(strings.Join(hints, " • ")))
+
+	return modalStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+)
+
+// codeOwnersPaths are the locations a CODEOWNERS file is looked up in, in
+// order of precedence, dir is the forge specific directory such as .github.
+func codeOwnersPaths(dir string) []string {
+	return []string{
+		dir + "/CODEOWNERS",
+		"CODEOWNERS",
+		"docs/CODEOWNERS",
+	}
+}
+
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// CodeOwners is a parsed CODEOWNERS file.
+type CodeOwners struct {
+	rules []codeOwnersRule
+}
+
+func ParseCodeOwners(content string) *CodeOwners {
+	codeOwners := &CodeOwners{}
+
+	for _, line := range strings.Split(content, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		// GitLab sections, such as [Docs] or ^[Optional][2] @owner
+		if strings.HasPrefix(fields[0], "[") || strings.HasPrefix(fields[0], "^[") {
+			continue
+		}
+
+		codeOwners.rules = append(codeOwners.rules, codeOwnersRule{
+			pattern: codeOwnersPattern(fields[0]),
+			owners:  fields[1:],
+		})
+	}
+
+	return codeOwners
+}
+
+// Owners returns the owners of the file, the last matching rule wins.
+func (c *CodeOwners) Owners(file string) []string {
+	file = strings.TrimPrefix(file, "/")
+
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchString(file) {
+			return c.rules[i].owners
+		}
+	}
+
+	return nil
+}
+
+// codeOwnersPattern translates a gitignore style pattern into a regexp
+// matching repository relative file paths.
+func codeOwnersPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	directory := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	switch {
+	case directory:
+		b.WriteString("/.*$")
+	case !strings.ContainsAny(pattern[strings.LastIndex(pattern, "/")+1:], "*?"):
+		// A name without globs may be a directory, owning everything below
+		// it. Globs don't cross slashes, docs/* only owns the files in docs.
+		b.WriteString("(?:/.*)?$")
+	default:
+		b.WriteString("$")
+	}
+
+	return regexp.MustCompile(b.String())
+}
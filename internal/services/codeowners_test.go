@@ -0,0 +1,119 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCodeOwnersPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		matches []string
+		misses  []string
+	}{
+		{
+			pattern: "*",
+			matches: []string{"main.go", "cmd/main.go"},
+		},
+		{
+			pattern: "*.go",
+			matches: []string{"main.go", "cmd/dr/main.go"},
+			misses:  []string{"main.go.orig", "README.md"},
+		},
+		{
+			pattern: "docs/*",
+			matches: []string{"docs/index.md"},
+			misses:  []string{"docs/x/y.md", "docs", "src/docs/index.md"},
+		},
+		{
+			pattern: "docs/**",
+			matches: []string{"docs/index.md", "docs/x/y.md"},
+			misses:  []string{"src/docs/index.md"},
+		},
+		{
+			pattern: "**/logs",
+			matches: []string{"logs", "build/logs", "build/logs/today.log"},
+			misses:  []string{"logs.txt"},
+		},
+		{
+			pattern: "apps/**/test.go",
+			matches: []string{"apps/test.go", "apps/a/b/test.go"},
+			misses:  []string{"apps/a/test.go.orig", "lib/apps/test.go"},
+		},
+		{
+			pattern: "/build/",
+			matches: []string{"build/main.go", "build/x/main.go"},
+			misses:  []string{"build", "src/build/main.go"},
+		},
+		{
+			pattern: "apps/",
+			matches: []string{"apps/main.go", "src/apps/x/main.go"},
+			misses:  []string{"apps", "application/main.go"},
+		},
+		{
+			pattern: "/Makefile",
+			matches: []string{"Makefile"},
+			misses:  []string{"src/Makefile", "Makefile.old"},
+		},
+		{
+			pattern: "internal",
+			matches: []string{"internal", "internal/x.go", "cmd/internal/x.go"},
+			misses:  []string{"internals/x.go"},
+		},
+		{
+			pattern: "src/main.?o",
+			matches: []string{"src/main.go"},
+			misses:  []string{"src/main.g/o", "src/main.go/x"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			pattern := codeOwnersPattern(test.pattern)
+			for _, path := range test.matches {
+				if !pattern.MatchString(path) {
+					t.Errorf("%s (%s) doesn't match %s", test.pattern, pattern, path)
+				}
+			}
+			for _, path := range test.misses {
+				if pattern.MatchString(path) {
+					t.Errorf("%s (%s) matches %s", test.pattern, pattern, path)
+				}
+			}
+		})
+	}
+}
+
+func TestCodeOwnersOwners(t *testing.T) {
+	codeOwners := ParseCodeOwners(`
+# Everything is the platform's, unless owned below.
+*              @lunarway/squad-platform
+docs/*         @lunarway/squad-docs    # only the top level
+/internal/pay/ @lunarway/squad-aura @alice
+
+[Optional]
+^[Release][2] @bob
+`)
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{file: "main.go", want: "[@lunarway/squad-platform]"},
+		{file: "docs/index.md", want: "[@lunarway/squad-docs]"},
+		{file: "docs/x/y.md", want: "[@lunarway/squad-platform]"},
+		{file: "/internal/pay/card.go", want: "[@lunarway/squad-aura @alice]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			if got := fmt.Sprint(codeOwners.Owners(test.file)); got != test.want {
+				t.Errorf("Owners() = %s, want %s", got, test.want)
+			}
+		})
+	}
+
+	if owners := ParseCodeOwners("").Owners("main.go"); owners != nil {
+		t.Errorf("Owners() without rules = %v, want none", owners)
+	}
+}
@@ -0,0 +1,544 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultGiteaBaseURL = "https://codeberg.org/api/v1"
+
+type GiteaClientOption func(*GiteaClient)
+
+// WithGiteaBaseURL points the client at a Gitea or Forgejo instance, either
+// the instance url (https://git.example.com) or its api root.
+func WithGiteaBaseURL(baseURL string) GiteaClientOption {
+	return func(g *GiteaClient) {
+		baseURL = strings.TrimSuffix(baseURL, "/")
+		if !strings.HasSuffix(baseURL, "/api/v1") {
+			baseURL += "/api/v1"
+		}
+
+		g.baseURL = baseURL
+	}
+}
+
+func WithGiteaToken(token string) GiteaClientOption {
+	return func(g *GiteaClient) {
+		g.token = token
+	}
+}
+
+func WithGiteaHTTPClient(httpClient *http.Client) GiteaClientOption {
+	return func(g *GiteaClient) {
+		g.httpClient = httpClient
+	}
+}
+
+// GiteaClient is a minimal client for the parts of the Gitea API, which
+// Forgejo shares, used for reviewing pull requests.
+type GiteaClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+func NewGiteaClient(opts ...GiteaClientOption) *GiteaClient {
+	client := &GiteaClient{
+		baseURL:    DefaultGiteaBaseURL,
+		httpClient: http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client
+}
+
+type giteaUser struct {
+	Login string `json:"login"`
+}
+
+type giteaTeam struct {
+	Name         string `json:"name"`
+	Organization struct {
+		Name string `json:"name"`
+	} `json:"organization"`
+}
+
+type giteaIssue struct {
+	Number     int `json:"number"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+type giteaPullRequest struct {
+	Number    int       `json:"number"`
+	HTMLURL   string    `json:"html_url"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	User      giteaUser `json:"user"`
+	Draft     bool      `json:"draft"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Labels    []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Head struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	RequestedReviewers      []giteaUser `json:"requested_reviewers"`
+	RequestedReviewersTeams []struct {
+		Name string `json:"name"`
+	} `json:"requested_reviewers_teams"`
+	Mergeable bool `json:"mergeable"`
+}
+
+type giteaComment struct {
+	ID        int64     `json:"id"`
+	HTMLURL   string    `json:"html_url"`
+	Body      string    `json:"body"`
+	User      giteaUser `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+	// Only set for review comments on the diff.
+	Path             string `json:"path"`
+	Position         int    `json:"position"`
+	OriginalPosition int    `json:"original_position"`
+}
+
+type giteaReviewSummary struct {
+	ID            int64     `json:"id"`
+	User          giteaUser `json:"user"`
+	State         string    `json:"state"`
+	Dismissed     bool      `json:"dismissed"`
+	CommentsCount int       `json:"comments_count"`
+}
+
+type giteaCommitStatus struct {
+	Context   string `json:"context"`
+	Status    string `json:"status"`
+	TargetURL string `json:"target_url"`
+}
+
+// giteaCombinedStatus holds the latest status of each context of a commit.
+type giteaCombinedStatus struct {
+	Statuses []giteaCommitStatus `json:"statuses"`
+}
+
+// ListReviewRequests returns the open pull requests where the authenticated
+// user is a requested reviewer.
+func (g *GiteaClient) ListReviewRequests(ctx context.Context) ([]PullRequestRef, error) {
+	refs := make([]PullRequestRef, 0)
+	next := g.baseURL + "/repos/issues/search?" + url.Values{
+		"type":             []string{"pulls"},
+		"state":            []string{"open"},
+		"review_requested": []string{"true"},
+		"limit":            []string{"50"},
+	}.Encode()
+
+	for next != "" {
+		var page []giteaIssue
+		link, err := g.getJSON(ctx, next, &page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search pull requests: %w", err)
+		}
+
+		for _, issue := range page {
+			refs = append(refs, PullRequestRef{
+				Repo:   issue.Repository.FullName,
+				Number: issue.Number,
+			})
+		}
+
+		next = link
+	}
+
+	return refs, nil
+}
+
+// GetReviewers returns the authenticated user and their teams.
+func (g *GiteaClient) GetReviewers(ctx context.Context) ([]string, error) {
+	var user giteaUser
+	if _, err := g.getJSON(ctx, g.baseURL+"/user", &user); err != nil {
+		return nil, fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	reviewers := []string{user.Login}
+	for next := g.baseURL + "/user/teams?limit=50"; next != ""; {
+		var page []giteaTeam
+		link, err := g.getJSON(ctx, next, &page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list teams: %w", err)
+		}
+
+		for _, team := range page {
+			reviewers = append(reviewers, fmt.Sprintf("@%s/%s", team.Organization.Name, team.Name))
+		}
+
+		next = link
+	}
+
+	return reviewers, nil
+}
+
+// GetPullRequest fetches the pull request along with its comments, commit
+// statuses and unified diff.
+func (g *GiteaClient) GetPullRequest(ctx context.Context, ref PullRequestRef) (*PullRequest, error) {
+	repoURL := g.repoURL(ref)
+
+	var pr giteaPullRequest
+	if _, err := g.getJSON(ctx, fmt.Sprintf("%s/pulls/%d", repoURL, ref.Number), &pr); err != nil {
+		return nil, fmt.Errorf("failed to get pull request %s: %w", ref, err)
+	}
+
+	comments, err := g.getComments(ctx, fmt.Sprintf("%s/issues/%d/comments", repoURL, ref.Number))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comments for %s: %w", ref, err)
+	}
+
+	reviews := make([]giteaReviewSummary, 0)
+	next := fmt.Sprintf("%s/pulls/%d/reviews", repoURL, ref.Number)
+	for next != "" {
+		var page []giteaReviewSummary
+		link, err := g.getJSON(ctx, next, &page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get reviews for %s: %w", ref, err)
+		}
+
+		reviews = append(reviews, page...)
+		next = link
+	}
+	verdicts := make(map[string]ReviewState)
+	for _, review := range reviews {
+		switch {
+		case review.Dismissed:
+			delete(verdicts, review.User.Login)
+		case review.State == "APPROVED":
+			verdicts[review.User.Login] = ReviewStateApproved
+		case review.State == "REQUEST_CHANGES":
+			verdicts[review.User.Login] = ReviewStateChangesRequested
+		}
+
+		if review.CommentsCount == 0 {
+			continue
+		}
+
+		reviewComments, err := g.getComments(ctx, fmt.Sprintf("%s/pulls/%d/reviews/%d/comments", repoURL, ref.Number, review.ID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get review comments for %s: %w", ref, err)
+		}
+
+		comments = append(comments, reviewComments...)
+	}
+	sort.SliceStable(comments, func(i, j int) bool {
+		return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+	})
+
+	checks := make([]Check, 0)
+	if pr.Head.SHA != "" {
+		// The combined status keeps only the latest status of each context,
+		// the statuses endpoint lists every one ever set.
+		var combined giteaCombinedStatus
+		if _, err := g.getJSON(ctx, fmt.Sprintf("%s/commits/%s/status", repoURL, pr.Head.SHA), &combined); err != nil {
+			return nil, fmt.Errorf("failed to get commit statuses for %s: %w", ref, err)
+		}
+
+		for _, status := range combined.Statuses {
+			check := giteaStatusCheck(status.Status)
+			check.Name = status.Context
+			check.URL = status.TargetURL
+
+			checks = append(checks, check)
+		}
+	}
+
+	diff, err := g.get(ctx, fmt.Sprintf("%s/pulls/%d.diff", repoURL, ref.Number))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diff for %s: %w", ref, err)
+	}
+
+	owner, _, _ := strings.Cut(ref.Repo, "/")
+	requestedReviewers := make([]string, 0, len(pr.RequestedReviewers)+len(pr.RequestedReviewersTeams))
+	for _, user := range pr.RequestedReviewers {
+		requestedReviewers = append(requestedReviewers, user.Login)
+	}
+	for _, team := range pr.RequestedReviewersTeams {
+		requestedReviewers = append(requestedReviewers, fmt.Sprintf("@%s/%s", owner, team.Name))
+	}
+
+	labels := make([]string, 0, len(pr.Labels))
+	for _, label := range pr.Labels {
+		labels = append(labels, label.Name)
+	}
+
+	// Gitea only reports conflicts, failing checks are left to the checks.
+	mergeState := MergeStateConflicting
+	if pr.Mergeable {
+		mergeState = MergeStateClean
+	}
+
+	return &PullRequest{
+		Ref:                ref,
+		URL:                pr.HTMLURL,
+		Title:              pr.Title,
+		Description:        pr.Body,
+		Author:             pr.User.Login,
+		Labels:             labels,
+		Draft:              pr.Draft,
+		BaseRef:            pr.Base.Ref,
+		HeadRef:            pr.Head.Ref,
+		HeadSHA:            pr.Head.SHA,
+		MergeState:         mergeState,
+		ReviewState:        summarizeReviews(verdicts),
+		CreatedAt:          pr.CreatedAt,
+		UpdatedAt:          pr.UpdatedAt,
+		RequestedReviewers: requestedReviewers,
+		Comments:           comments,
+		Checks:             checks,
+		Files:              parseFiles(string(diff.body)),
+		Diff:               string(diff.body),
+	}, nil
+}
+
+// getComments fetches every page of issue or review comments, Gitea has no
+// replies so review comments are threaded by the line they are on.
+func (g *GiteaClient) getComments(ctx context.Context, endpoint string) ([]Comment, error) {
+	comments := make([]Comment, 0)
+
+	for next := endpoint; next != ""; {
+		var page []giteaComment
+		link, err := g.getJSON(ctx, next, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, comment := range page {
+			var (
+				threadID string
+				side     DiffSide
+				line     = comment.Position
+			)
+			if comment.Path != "" {
+				threadID = fmt.Sprintf("%s:%d", comment.Path, comment.Position)
+				side = DiffSideNew
+				// Comments on removed lines only have a position on the old
+				// side.
+				if comment.Position == 0 && comment.OriginalPosition > 0 {
+					side, line = DiffSideOld, comment.OriginalPosition
+					threadID = fmt.Sprintf("%s:-%d", comment.Path, line)
+				}
+			}
+
+			comments = append(comments, Comment{
+				ID:        strconv.FormatInt(comment.ID, 10),
+				Author:    comment.User.Login,
+				Body:      comment.Body,
+				CreatedAt: comment.CreatedAt,
+				URL:       comment.HTMLURL,
+				ThreadID:  threadID,
+				Path:      comment.Path,
+				Side:      side,
+				Line:      line,
+			})
+		}
+
+		next = link
+	}
+
+	return comments, nil
+}
+
+// giteaStatusCheck maps the state of a Gitea commit status onto a check.
+func giteaStatusCheck(status string) Check {
+	switch status {
+	case "pending":
+		return Check{Status: CheckStatusInProgress}
+	case "success":
+		return Check{Status: CheckStatusCompleted, Conclusion: CheckConclusionSuccess}
+	case "error", "failure":
+		return Check{Status: CheckStatusCompleted, Conclusion: CheckConclusionFailure}
+	default:
+		return Check{Status: CheckStatusCompleted, Conclusion: CheckConclusionNeutral}
+	}
+}
+
+// GetCodeOwners returns the CODEOWNERS file on the default branch of the
+// repository.
+func (g *GiteaClient) GetCodeOwners(ctx context.Context, repo string) (string, error) {
+	repoURL := g.repoURL(PullRequestRef{Repo: repo})
+
+	for _, path := range codeOwnersPaths(".gitea") {
+		resp, err := g.get(ctx, repoURL+"/raw/"+path)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to get CODEOWNERS for %s: %w", repo, err)
+		}
+
+		return string(resp.body), nil
+	}
+
+	return "", nil
+}
+
+type giteaReview struct {
+	Event    string               `json:"event"`
+	Body     string               `json:"body,omitempty"`
+	Comments []giteaReviewComment `json:"comments,omitempty"`
+}
+
+type giteaReviewComment struct {
+	Path        string `json:"path"`
+	Body        string `json:"body"`
+	OldPosition int    `json:"old_position,omitempty"`
+	NewPosition int    `json:"new_position,omitempty"`
+}
+
+// SubmitReview creates and submits a review on the pull request, event is one
+// of APPROVED, REQUEST_CHANGES or COMMENT.
+func (g *GiteaClient) SubmitReview(ctx context.Context, ref PullRequestRef, event string, body string, comments []ReviewComment) error {
+	review := giteaReview{Event: event, Body: body}
+	for _, comment := range comments {
+		reviewComment := giteaReviewComment{Path: comment.Path, Body: comment.Body}
+		if comment.Side == DiffSideOld {
+			reviewComment.OldPosition = comment.Line
+		} else {
+			reviewComment.NewPosition = comment.Line
+		}
+
+		review.Comments = append(review.Comments, reviewComment)
+	}
+
+	endpoint := fmt.Sprintf("%s/pulls/%d/reviews", g.repoURL(ref), ref.Number)
+	if _, err := g.send(ctx, http.MethodPost, endpoint, review); err != nil {
+		return fmt.Errorf("failed to submit review for %s: %w", ref, err)
+	}
+
+	return nil
+}
+
+type giteaMerge struct {
+	Do                     MergeMethod `json:"Do"`
+	MergeWhenChecksSucceed bool        `json:"merge_when_checks_succeed,omitempty"`
+}
+
+// MergePullRequest merges the pull request, or schedules it to merge when its
+// checks succeed.
+func (g *GiteaClient) MergePullRequest(ctx context.Context, ref PullRequestRef, method MergeMethod, whenChecksSucceed bool) error {
+	endpoint := fmt.Sprintf("%s/pulls/%d/merge", g.repoURL(ref), ref.Number)
+	merge := giteaMerge{Do: method, MergeWhenChecksSucceed: whenChecksSucceed}
+	if _, err := g.send(ctx, http.MethodPost, endpoint, merge); err != nil {
+		return fmt.Errorf("failed to merge %s: %w", ref, err)
+	}
+
+	return nil
+}
+
+func (g *GiteaClient) ClosePullRequest(ctx context.Context, ref PullRequestRef) error {
+	update := struct {
+		State string `json:"state"`
+	}{
+		State: "closed",
+	}
+
+	endpoint := fmt.Sprintf("%s/pulls/%d", g.repoURL(ref), ref.Number)
+	if _, err := g.send(ctx, http.MethodPatch, endpoint, update); err != nil {
+		return fmt.Errorf("failed to close %s: %w", ref, err)
+	}
+
+	return nil
+}
+
+func (g *GiteaClient) repoURL(ref PullRequestRef) string {
+	owner, repo, _ := strings.Cut(ref.Repo, "/")
+
+	return fmt.Sprintf("%s/repos/%s/%s", g.baseURL, url.PathEscape(owner), url.PathEscape(repo))
+}
+
+type giteaResponse struct {
+	body []byte
+	next string
+}
+
+func (g *GiteaClient) getJSON(ctx context.Context, endpoint string, out any) (next string, err error) {
+	resp, err := g.get(ctx, endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	if err := json.Unmarshal(resp.body, out); err != nil {
+		return "", fmt.Errorf("failed to decode response from %s: %w", endpoint, err)
+	}
+
+	return resp.next, nil
+}
+
+func (g *GiteaClient) get(ctx context.Context, endpoint string) (*giteaResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.do(req)
+}
+
+func (g *GiteaClient) send(ctx context.Context, method string, endpoint string, in any) (*giteaResponse, error) {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	return g.do(req)
+}
+
+func (g *GiteaClient) do(req *http.Request) (*giteaResponse, error) {
+	if g.token != "" {
+		req.Header.Set("Authorization", "token "+g.token)
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("gitea: %s %s: %w", req.Method, req.URL, ErrNotFound)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("gitea: %s %s returned %s: %s", req.Method, req.URL, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return &giteaResponse{
+		body: body,
+		next: parseNextLink(resp.Header.Get("Link")),
+	}, nil
+}
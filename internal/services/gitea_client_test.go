@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGiteaClientGetPullRequestChecks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/repos/platform/dr/pulls/7", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"number": 7, "title": "Bump x", "head": {"ref": "bump-x", "sha": "abc123"}, "mergeable": true}`)
+	})
+	mux.HandleFunc("GET /api/v1/repos/platform/dr/issues/7/comments", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("GET /api/v1/repos/platform/dr/pulls/7/reviews", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("GET /api/v1/repos/platform/dr/pulls/7.diff", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "")
+	})
+	mux.HandleFunc("GET /api/v1/repos/platform/dr/commits/abc123/statuses", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the status history was requested, want the combined status")
+	})
+	mux.HandleFunc("GET /api/v1/repos/platform/dr/commits/abc123/status", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+  "state": "failure",
+  "sha": "abc123",
+  "total_count": 2,
+  "statuses": [
+    {"context": "ci/build", "status": "success", "target_url": "https://ci.example.com/1"},
+    {"context": "ci/test", "status": "failure", "target_url": "https://ci.example.com/2"}
+  ]
+}`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := NewGiteaClient(WithGiteaBaseURL(server.URL), WithGiteaHTTPClient(server.Client()))
+	pr, err := client.GetPullRequest(context.Background(), PullRequestRef{Repo: "platform/dr", Number: 7})
+	if err != nil {
+		t.Fatalf("GetPullRequest() error = %v", err)
+	}
+
+	want := []string{
+		"ci/build:completed:success:https://ci.example.com/1",
+		"ci/test:completed:failure:https://ci.example.com/2",
+	}
+	if len(pr.Checks) != len(want) {
+		t.Fatalf("got %d checks, want %d", len(pr.Checks), len(want))
+	}
+	for i, check := range pr.Checks {
+		if got := fmt.Sprintf("%s:%s:%s:%s", check.Name, check.Status, check.Conclusion, check.URL); got != want[i] {
+			t.Errorf("check %d = %q, want %q", i, got, want[i])
+		}
+	}
+}
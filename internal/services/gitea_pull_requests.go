@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"fmt"
+)
+
+// GiteaPullRequestProvider reviews pull requests on Gitea or Forgejo.
+type GiteaPullRequestProvider struct {
+	client *GiteaClient
+	queue  pullRequestQueue
+}
+
+func NewGiteaPullRequestProvider(client *GiteaClient) *GiteaPullRequestProvider {
+	return &GiteaPullRequestProvider{
+		client: client,
+	}
+}
+
+func (g *GiteaPullRequestProvider) List(ctx context.Context) ([]PullRequestRef, error) {
+	return g.client.ListReviewRequests(ctx)
+}
+
+func (g *GiteaPullRequestProvider) Get(ctx context.Context, ref PullRequestRef) (*PullRequest, error) {
+	return g.client.GetPullRequest(ctx, ref)
+}
+
+func (g *GiteaPullRequestProvider) GetCodeOwners(ctx context.Context, repo string) (string, error) {
+	return g.client.GetCodeOwners(ctx, repo)
+}
+
+func (g *GiteaPullRequestProvider) GetReviewers(ctx context.Context) ([]string, error) {
+	return g.client.GetReviewers(ctx)
+}
+
+func (g *GiteaPullRequestProvider) GetNext(ctx context.Context) (*PullRequest, bool, error) {
+	return g.queue.next(ctx, g.List, g.Get)
+}
+
+func (g *GiteaPullRequestProvider) Act(ctx context.Context, ref PullRequestRef, action PullRequestAction) error {
+	var event string
+	switch action.Kind {
+	case PullRequestActionMerge:
+		return g.client.MergePullRequest(ctx, ref, action.MergeMethod, false)
+	case PullRequestActionAutoMerge:
+		return g.client.MergePullRequest(ctx, ref, action.MergeMethod, true)
+	case PullRequestActionClose:
+		return g.client.ClosePullRequest(ctx, ref)
+	case PullRequestActionApprove:
+		event = "APPROVED"
+	case PullRequestActionRequestChanges:
+		event = "REQUEST_CHANGES"
+	case PullRequestActionComment:
+		event = "COMMENT"
+	default:
+		return fmt.Errorf("unsupported action: %s", action.Kind)
+	}
+
+	return g.client.SubmitReview(ctx, ref, event, action.Body, action.Comments)
+}
+
+var _ PullRequestProvider = &GiteaPullRequestProvider{}
+var _ CodeOwnersReader = &GiteaPullRequestProvider{}
+var _ ReviewersReader = &GiteaPullRequestProvider{}
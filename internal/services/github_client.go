@@ -0,0 +1,637 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultGitHubBaseURL = "https://api.github.com"
+
+type GitHubClientOption func(*GitHubClient)
+
+// WithGitHubBaseURL points the client at another API root, such as a GitHub
+// Enterprise instance (https://github.example.com/api/v3) or a test server.
+func WithGitHubBaseURL(baseURL string) GitHubClientOption {
+	return func(g *GitHubClient) {
+		g.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+func WithGitHubToken(token string) GitHubClientOption {
+	return func(g *GitHubClient) {
+		g.token = token
+	}
+}
+
+func WithGitHubHTTPClient(httpClient *http.Client) GitHubClientOption {
+	return func(g *GitHubClient) {
+		g.httpClient = httpClient
+	}
+}
+
+// GitHubClient is a minimal client for the parts of the GitHub REST API used
+// for reviewing pull requests.
+type GitHubClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+func NewGitHubClient(opts ...GitHubClientOption) *GitHubClient {
+	client := &GitHubClient{
+		baseURL:    DefaultGitHubBaseURL,
+		httpClient: http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client
+}
+
+type gitHubUser struct {
+	Login string `json:"login"`
+}
+
+type gitHubTeam struct {
+	Slug         string     `json:"slug"`
+	Organization gitHubUser `json:"organization"`
+}
+
+type gitHubSearchIssues struct {
+	Items []struct {
+		Number        int    `json:"number"`
+		RepositoryURL string `json:"repository_url"`
+	} `json:"items"`
+}
+
+type gitHubPullRequest struct {
+	Number    int        `json:"number"`
+	NodeID    string     `json:"node_id"`
+	HTMLURL   string     `json:"html_url"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	User      gitHubUser `json:"user"`
+	Draft     bool       `json:"draft"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Labels    []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Head struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	RequestedReviewers []gitHubUser `json:"requested_reviewers"`
+	RequestedTeams     []struct {
+		Slug string `json:"slug"`
+	} `json:"requested_teams"`
+	MergeableState string `json:"mergeable_state"`
+}
+
+type gitHubComment struct {
+	ID        int64      `json:"id"`
+	HTMLURL   string     `json:"html_url"`
+	Body      string     `json:"body"`
+	User      gitHubUser `json:"user"`
+	CreatedAt time.Time  `json:"created_at"`
+	// Only set for review comments on the diff.
+	Path        string `json:"path"`
+	Line        int    `json:"line"`
+	Side        string `json:"side"`
+	InReplyToID int64  `json:"in_reply_to_id"`
+}
+
+type gitHubReviewSummary struct {
+	User  gitHubUser `json:"user"`
+	State string     `json:"state"`
+}
+
+type gitHubCheckRuns struct {
+	CheckRuns []struct {
+		Name       string `json:"name"`
+		HTMLURL    string `json:"html_url"`
+		Status     string `json:"status"`
+		Conclusion string `json:"conclusion"`
+	} `json:"check_runs"`
+}
+
+// ListReviewRequests returns the open pull requests where the authenticated
+// user, or one of their teams, is a requested reviewer.
+func (g *GitHubClient) ListReviewRequests(ctx context.Context) ([]PullRequestRef, error) {
+	return g.SearchPullRequests(ctx, "is:pr is:open review-requested:@me")
+}
+
+// GetReviewers returns the authenticated user and their teams,
+// review-requested:@me matches requests to either.
+func (g *GitHubClient) GetReviewers(ctx context.Context) ([]string, error) {
+	var user gitHubUser
+	if _, err := g.getJSON(ctx, g.baseURL+"/user", &user); err != nil {
+		return nil, fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	reviewers := []string{user.Login}
+	for next := g.baseURL + "/user/teams?per_page=100"; next != ""; {
+		var page []gitHubTeam
+		link, err := g.getJSON(ctx, next, &page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list teams: %w", err)
+		}
+
+		for _, team := range page {
+			reviewers = append(reviewers, fmt.Sprintf("@%s/%s", team.Organization.Login, team.Slug))
+		}
+
+		next = link
+	}
+
+	return reviewers, nil
+}
+
+// SearchPullRequests runs an issue search and returns every pull request it
+// matches, following pagination.
+func (g *GitHubClient) SearchPullRequests(ctx context.Context, query string) ([]PullRequestRef, error) {
+	refs := make([]PullRequestRef, 0)
+
+	next := g.baseURL + "/search/issues?" + url.Values{
+		"q":        []string{query},
+		"per_page": []string{"100"},
+	}.Encode()
+
+	for next != "" {
+		var result gitHubSearchIssues
+		link, err := g.getJSON(ctx, next, &result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search pull requests: %w", err)
+		}
+
+		for _, item := range result.Items {
+			repo, err := parseRepositoryURL(item.RepositoryURL)
+			if err != nil {
+				return nil, err
+			}
+
+			refs = append(refs, PullRequestRef{
+				Repo:   repo,
+				Number: item.Number,
+			})
+		}
+
+		next = link
+	}
+
+	return refs, nil
+}
+
+// GetPullRequest fetches the pull request along with its comments, check runs
+// and unified diff.
+func (g *GitHubClient) GetPullRequest(ctx context.Context, ref PullRequestRef) (*PullRequest, error) {
+	repoURL := g.repoURL(ref)
+
+	var pr gitHubPullRequest
+	if _, err := g.getJSON(ctx, fmt.Sprintf("%s/pulls/%d", repoURL, ref.Number), &pr); err != nil {
+		return nil, fmt.Errorf("failed to get pull request %s: %w", ref, err)
+	}
+
+	comments := make([]Comment, 0)
+	for _, endpoint := range []string{
+		fmt.Sprintf("%s/issues/%d/comments?per_page=100", repoURL, ref.Number),
+		fmt.Sprintf("%s/pulls/%d/comments?per_page=100", repoURL, ref.Number),
+	} {
+		page, err := g.getComments(ctx, endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get comments for %s: %w", ref, err)
+		}
+
+		comments = append(comments, page...)
+	}
+	sort.SliceStable(comments, func(i, j int) bool {
+		return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+	})
+
+	reviewState, err := g.getReviewState(ctx, fmt.Sprintf("%s/pulls/%d/reviews?per_page=100", repoURL, ref.Number))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviews for %s: %w", ref, err)
+	}
+
+	checks := make([]Check, 0)
+	if pr.Head.SHA != "" {
+		var checkRuns gitHubCheckRuns
+		if _, err := g.getJSON(ctx, fmt.Sprintf("%s/commits/%s/check-runs?per_page=100", repoURL, pr.Head.SHA), &checkRuns); err != nil {
+			return nil, fmt.Errorf("failed to get check runs for %s: %w", ref, err)
+		}
+
+		for _, run := range checkRuns.CheckRuns {
+			checks = append(checks, Check{
+				Name:       run.Name,
+				Status:     CheckStatus(run.Status),
+				Conclusion: CheckConclusion(run.Conclusion),
+				URL:        run.HTMLURL,
+			})
+		}
+	}
+
+	diff, err := g.get(ctx, fmt.Sprintf("%s/pulls/%d", repoURL, ref.Number), "application/vnd.github.diff")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diff for %s: %w", ref, err)
+	}
+
+	owner, _, _ := strings.Cut(ref.Repo, "/")
+	requestedReviewers := make([]string, 0, len(pr.RequestedReviewers)+len(pr.RequestedTeams))
+	for _, user := range pr.RequestedReviewers {
+		requestedReviewers = append(requestedReviewers, user.Login)
+	}
+	for _, team := range pr.RequestedTeams {
+		requestedReviewers = append(requestedReviewers, fmt.Sprintf("@%s/%s", owner, team.Slug))
+	}
+
+	labels := make([]string, 0, len(pr.Labels))
+	for _, label := range pr.Labels {
+		labels = append(labels, label.Name)
+	}
+
+	return &PullRequest{
+		Ref:                ref,
+		URL:                pr.HTMLURL,
+		Title:              pr.Title,
+		Description:        pr.Body,
+		Author:             pr.User.Login,
+		Labels:             labels,
+		Draft:              pr.Draft,
+		BaseRef:            pr.Base.Ref,
+		HeadRef:            pr.Head.Ref,
+		HeadSHA:            pr.Head.SHA,
+		MergeState:         gitHubMergeState(pr.MergeableState),
+		ReviewState:        reviewState,
+		CreatedAt:          pr.CreatedAt,
+		UpdatedAt:          pr.UpdatedAt,
+		RequestedReviewers: requestedReviewers,
+		Comments:           comments,
+		Checks:             checks,
+		Files:              parseFiles(string(diff.body)),
+		Diff:               string(diff.body),
+	}, nil
+}
+
+// gitHubMergeState maps the mergeable_state of a pull request, which GitHub
+// leaves unknown until it has been computed in the background.
+func gitHubMergeState(state string) MergeState {
+	switch state {
+	case "clean", "has_hooks":
+		return MergeStateClean
+	case "dirty":
+		return MergeStateConflicting
+	case "blocked", "draft":
+		return MergeStateBlocked
+	case "behind":
+		return MergeStateBehind
+	case "unstable":
+		return MergeStateUnstable
+	default:
+		return MergeStateUnknown
+	}
+}
+
+// getReviewState follows the reviews in the order they were submitted,
+// comments leave the verdict of a reviewer as it was and dismissals reset it.
+func (g *GitHubClient) getReviewState(ctx context.Context, endpoint string) (ReviewState, error) {
+	verdicts := make(map[string]ReviewState)
+
+	for next := endpoint; next != ""; {
+		var page []gitHubReviewSummary
+		link, err := g.getJSON(ctx, next, &page)
+		if err != nil {
+			return "", err
+		}
+
+		for _, review := range page {
+			switch review.State {
+			case "APPROVED":
+				verdicts[review.User.Login] = ReviewStateApproved
+			case "CHANGES_REQUESTED":
+				verdicts[review.User.Login] = ReviewStateChangesRequested
+			case "DISMISSED":
+				delete(verdicts, review.User.Login)
+			}
+		}
+
+		next = link
+	}
+
+	return summarizeReviews(verdicts), nil
+}
+
+// getComments fetches every page of issue or review comments, review comments
+// are threaded by the comment they reply to.
+func (g *GitHubClient) getComments(ctx context.Context, endpoint string) ([]Comment, error) {
+	comments := make([]Comment, 0)
+
+	for next := endpoint; next != ""; {
+		var page []gitHubComment
+		link, err := g.getJSON(ctx, next, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, comment := range page {
+			var (
+				threadID string
+				side     DiffSide
+			)
+			if comment.Path != "" {
+				threadID = strconv.FormatInt(comment.ID, 10)
+				if comment.InReplyToID != 0 {
+					threadID = strconv.FormatInt(comment.InReplyToID, 10)
+				}
+				side = DiffSideNew
+				if comment.Side == "LEFT" {
+					side = DiffSideOld
+				}
+			}
+
+			comments = append(comments, Comment{
+				ID:        strconv.FormatInt(comment.ID, 10),
+				Author:    comment.User.Login,
+				Body:      comment.Body,
+				CreatedAt: comment.CreatedAt,
+				URL:       comment.HTMLURL,
+				ThreadID:  threadID,
+				Path:      comment.Path,
+				Side:      side,
+				Line:      comment.Line,
+			})
+		}
+
+		next = link
+	}
+
+	return comments, nil
+}
+
+// GetCodeOwners returns the CODEOWNERS file on the default branch of the
+// repository.
+func (g *GitHubClient) GetCodeOwners(ctx context.Context, repo string) (string, error) {
+	repoURL := g.repoURL(PullRequestRef{Repo: repo})
+
+	for _, path := range codeOwnersPaths(".github") {
+		resp, err := g.get(ctx, repoURL+"/contents/"+path, "application/vnd.github.raw")
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to get CODEOWNERS for %s: %w", repo, err)
+		}
+
+		return string(resp.body), nil
+	}
+
+	return "", nil
+}
+
+type gitHubReview struct {
+	Event    string                `json:"event"`
+	Body     string                `json:"body,omitempty"`
+	Comments []gitHubReviewComment `json:"comments,omitempty"`
+}
+
+type gitHubReviewComment struct {
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line,omitempty"`
+	StartSide string `json:"start_side,omitempty"`
+	Line      int    `json:"line"`
+	Side      string `json:"side"`
+	Body      string `json:"body"`
+}
+
+// SubmitReview creates and submits a review on the pull request, event is one
+// of APPROVE, REQUEST_CHANGES or COMMENT.
+func (g *GitHubClient) SubmitReview(ctx context.Context, ref PullRequestRef, event string, body string, comments []ReviewComment) error {
+	review := gitHubReview{Event: event, Body: body}
+	for _, comment := range comments {
+		side := "RIGHT"
+		if comment.Side == DiffSideOld {
+			side = "LEFT"
+		}
+
+		reviewComment := gitHubReviewComment{
+			Path: comment.Path,
+			Line: comment.Line,
+			Side: side,
+			Body: comment.Body,
+		}
+		if comment.StartLine > 0 && comment.StartLine < comment.Line {
+			reviewComment.StartLine = comment.StartLine
+			reviewComment.StartSide = side
+		}
+
+		review.Comments = append(review.Comments, reviewComment)
+	}
+
+	endpoint := fmt.Sprintf("%s/pulls/%d/reviews", g.repoURL(ref), ref.Number)
+	if _, err := g.send(ctx, http.MethodPost, endpoint, review); err != nil {
+		return fmt.Errorf("failed to submit review for %s: %w", ref, err)
+	}
+
+	return nil
+}
+
+func (g *GitHubClient) MergePullRequest(ctx context.Context, ref PullRequestRef, method MergeMethod) error {
+	merge := struct {
+		MergeMethod MergeMethod `json:"merge_method"`
+	}{
+		MergeMethod: method,
+	}
+
+	endpoint := fmt.Sprintf("%s/pulls/%d/merge", g.repoURL(ref), ref.Number)
+	if _, err := g.send(ctx, http.MethodPut, endpoint, merge); err != nil {
+		return fmt.Errorf("failed to merge %s: %w", ref, err)
+	}
+
+	return nil
+}
+
+// EnableAutoMerge merges the pull request once its requirements are met, the
+// REST API has no endpoint for it so it goes through GraphQL.
+func (g *GitHubClient) EnableAutoMerge(ctx context.Context, ref PullRequestRef, method MergeMethod) error {
+	var pr gitHubPullRequest
+	if _, err := g.getJSON(ctx, fmt.Sprintf("%s/pulls/%d", g.repoURL(ref), ref.Number), &pr); err != nil {
+		return fmt.Errorf("failed to get pull request %s: %w", ref, err)
+	}
+
+	query := struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}{
+		Query: `mutation($id: ID!, $method: PullRequestMergeMethod!) {
+  enablePullRequestAutoMerge(input: {pullRequestId: $id, mergeMethod: $method}) {
+    clientMutationId
+  }
+}`,
+		Variables: map[string]any{
+			"id":     pr.NodeID,
+			"method": strings.ToUpper(string(method)),
+		},
+	}
+
+	resp, err := g.send(ctx, http.MethodPost, g.graphQLURL(), query)
+	if err != nil {
+		return fmt.Errorf("failed to enable auto-merge for %s: %w", ref, err)
+	}
+
+	// GraphQL reports errors in the body of a successful response.
+	var result struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(resp.body, &result); err != nil {
+		return fmt.Errorf("failed to decode auto-merge response for %s: %w", ref, err)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("failed to enable auto-merge for %s: %s", ref, result.Errors[0].Message)
+	}
+
+	return nil
+}
+
+func (g *GitHubClient) ClosePullRequest(ctx context.Context, ref PullRequestRef) error {
+	update := struct {
+		State string `json:"state"`
+	}{
+		State: "closed",
+	}
+
+	endpoint := fmt.Sprintf("%s/pulls/%d", g.repoURL(ref), ref.Number)
+	if _, err := g.send(ctx, http.MethodPatch, endpoint, update); err != nil {
+		return fmt.Errorf("failed to close %s: %w", ref, err)
+	}
+
+	return nil
+}
+
+// graphQLURL is the GraphQL endpoint next to the REST API, GitHub Enterprise
+// serves it from /api/graphql rather than /api/v3/graphql.
+func (g *GitHubClient) graphQLURL() string {
+	if base, ok := strings.CutSuffix(g.baseURL, "/api/v3"); ok {
+		return base + "/api/graphql"
+	}
+
+	return g.baseURL + "/graphql"
+}
+
+func (g *GitHubClient) repoURL(ref PullRequestRef) string {
+	owner, repo, _ := strings.Cut(ref.Repo, "/")
+
+	return fmt.Sprintf("%s/repos/%s/%s", g.baseURL, url.PathEscape(owner), url.PathEscape(repo))
+}
+
+type gitHubResponse struct {
+	body []byte
+	next string
+}
+
+func (g *GitHubClient) getJSON(ctx context.Context, endpoint string, out any) (next string, err error) {
+	resp, err := g.get(ctx, endpoint, "application/vnd.github+json")
+	if err != nil {
+		return "", err
+	}
+
+	if err := json.Unmarshal(resp.body, out); err != nil {
+		return "", fmt.Errorf("failed to decode response from %s: %w", endpoint, err)
+	}
+
+	return resp.next, nil
+}
+
+func (g *GitHubClient) get(ctx context.Context, endpoint string, accept string) (*gitHubResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", accept)
+
+	return g.do(req)
+}
+
+func (g *GitHubClient) send(ctx context.Context, method string, endpoint string, in any) (*gitHubResponse, error) {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	return g.do(req)
+}
+
+func (g *GitHubClient) do(req *http.Request) (*gitHubResponse, error) {
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("github: %s %s: %w", req.Method, req.URL, ErrNotFound)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("github: %s %s returned %s: %s", req.Method, req.URL, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return &gitHubResponse{
+		body: body,
+		next: parseNextLink(resp.Header.Get("Link")),
+	}, nil
+}
+
+var linkNextRegexp = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+func parseNextLink(header string) string {
+	match := linkNextRegexp.FindStringSubmatch(header)
+	if match == nil {
+		return ""
+	}
+
+	return match[1]
+}
+
+func parseRepositoryURL(repositoryURL string) (repo string, err error) {
+	parts := strings.Split(strings.TrimSuffix(repositoryURL, "/"), "/")
+	if len(parts) < 3 || parts[len(parts)-3] != "repos" {
+		return "", fmt.Errorf("unexpected repository url: %s", repositoryURL)
+	}
+
+	return parts[len(parts)-2] + "/" + parts[len(parts)-1], nil
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newGitHubTestClient serves the handlers as a stand-in for api.github.com,
+// with the base URL under /api/v3 as on GitHub Enterprise.
+func newGitHubTestClient(t *testing.T, handlers map[string]http.HandlerFunc) *GitHubClient {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	for pattern, handler := range handlers {
+		mux.HandleFunc(pattern, handler)
+	}
+	server := httptest.NewServer(http.StripPrefix("/api/v3", mux))
+	t.Cleanup(server.Close)
+
+	return NewGitHubClient(
+		WithGitHubBaseURL(server.URL+"/api/v3/"),
+		WithGitHubToken("token"),
+		WithGitHubHTTPClient(server.Client()),
+	)
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, v any) {
+	t.Helper()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Errorf("encoding response: %v", err)
+	}
+}
+
+func TestGitHubClientListReviewRequests(t *testing.T) {
+	var serverURL string
+	client := newGitHubTestClient(t, map[string]http.HandlerFunc{
+		"GET /search/issues": func(w http.ResponseWriter, r *http.Request) {
+			if got := r.Header.Get("Authorization"); got != "Bearer token" {
+				t.Errorf("Authorization = %q, want %q", got, "Bearer token")
+			}
+			if got, want := r.URL.Query().Get("q"), "is:pr is:open review-requested:@me"; got != want {
+				t.Errorf("q = %q, want %q", got, want)
+			}
+
+			if r.URL.Query().Get("page") == "2" {
+				writeJSON(t, w, map[string]any{"items": []map[string]any{
+					{"number": 3, "repository_url": serverURL + "/api/v3/repos/lunarway/dr"},
+				}})
+				return
+			}
+
+			next := fmt.Sprintf("%s/api/v3/search/issues?%s&page=2", serverURL, r.URL.RawQuery)
+			w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next", <%s>; rel="last"`, next, next))
+			writeJSON(t, w, map[string]any{"items": []map[string]any{
+				{"number": 1, "repository_url": serverURL + "/api/v3/repos/lunarway/dr"},
+				{"number": 2, "repository_url": serverURL + "/api/v3/repos/lunarway/shuttle"},
+			}})
+		},
+	})
+	serverURL = strings.TrimSuffix(client.baseURL, "/api/v3")
+
+	refs, err := client.ListReviewRequests(context.Background())
+	if err != nil {
+		t.Fatalf("ListReviewRequests() error = %v", err)
+	}
+
+	want := []PullRequestRef{
+		{Repo: "lunarway/dr", Number: 1},
+		{Repo: "lunarway/shuttle", Number: 2},
+		{Repo: "lunarway/dr", Number: 3},
+	}
+	if fmt.Sprint(refs) != fmt.Sprint(want) {
+		t.Errorf("ListReviewRequests() = %v, want %v", refs, want)
+	}
+}
+
+const gitHubTestDiff = `diff --git a/main.go b/main.go
+index 1..2 100644
+--- a/main.go
++++ b/main.go
+@@ -1,2 +1,2 @@
+ package main
+-var x = 1
++var x = 2
+`
+
+func TestGitHubClientGetPullRequest(t *testing.T) {
+	client := newGitHubTestClient(t, map[string]http.HandlerFunc{
+		"GET /repos/lunarway/dr/pulls/7": func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Accept") == "application/vnd.github.diff" {
+				fmt.Fprint(w, gitHubTestDiff)
+				return
+			}
+			writeJSON(t, w, map[string]any{
+				"number":          7,
+				"html_url":        "https://github.com/lunarway/dr/pull/7",
+				"title":           "Bump x",
+				"body":            "Bumps x to 2.",
+				"user":            map[string]any{"login": "alice"},
+				"labels":          []map[string]any{{"name": "dependencies"}},
+				"base":            map[string]any{"ref": "main"},
+				"head":            map[string]any{"ref": "bump-x", "sha": "abc123"},
+				"mergeable_state": "dirty",
+				"requested_reviewers": []map[string]any{
+					{"login": "bob"},
+				},
+				"requested_teams": []map[string]any{
+					{"slug": "squad"},
+				},
+			})
+		},
+		"GET /repos/lunarway/dr/issues/7/comments": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(t, w, []map[string]any{
+				{"id": 1, "body": "Looks good", "user": map[string]any{"login": "bob"}, "created_at": "2024-01-02T00:00:00Z"},
+			})
+		},
+		"GET /repos/lunarway/dr/pulls/7/comments": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(t, w, []map[string]any{
+				{"id": 2, "body": "Why 2?", "user": map[string]any{"login": "carol"}, "created_at": "2024-01-01T00:00:00Z", "path": "main.go", "line": 2, "side": "LEFT"},
+				{"id": 3, "body": "Because", "user": map[string]any{"login": "alice"}, "created_at": "2024-01-03T00:00:00Z", "path": "main.go", "line": 2, "side": "LEFT", "in_reply_to_id": 2},
+			})
+		},
+		"GET /repos/lunarway/dr/pulls/7/reviews": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(t, w, []map[string]any{
+				{"user": map[string]any{"login": "bob"}, "state": "APPROVED"},
+				{"user": map[string]any{"login": "carol"}, "state": "CHANGES_REQUESTED"},
+			})
+		},
+		"GET /repos/lunarway/dr/commits/abc123/check-runs": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(t, w, map[string]any{"check_runs": []map[string]any{
+				{"name": "build", "status": "completed", "conclusion": "success"},
+				{"name": "test", "status": "completed", "conclusion": "failure"},
+				{"name": "lint", "status": "in_progress"},
+			}})
+		},
+	})
+
+	pr, err := client.GetPullRequest(context.Background(), PullRequestRef{Repo: "lunarway/dr", Number: 7})
+	if err != nil {
+		t.Fatalf("GetPullRequest() error = %v", err)
+	}
+
+	if pr.Title != "Bump x" || pr.Description != "Bumps x to 2." || pr.Author != "alice" {
+		t.Errorf("GetPullRequest() title, description, author = %q, %q, %q", pr.Title, pr.Description, pr.Author)
+	}
+	if pr.HeadSHA != "abc123" || pr.BaseRef != "main" || pr.HeadRef != "bump-x" {
+		t.Errorf("GetPullRequest() refs = %q, %q, %q", pr.BaseRef, pr.HeadRef, pr.HeadSHA)
+	}
+	if pr.MergeState != MergeStateConflicting {
+		t.Errorf("MergeState = %q, want %q", pr.MergeState, MergeStateConflicting)
+	}
+	if pr.ReviewState != ReviewStateChangesRequested {
+		t.Errorf("ReviewState = %q, want %q", pr.ReviewState, ReviewStateChangesRequested)
+	}
+	if got, want := strings.Join(pr.RequestedReviewers, ","), "bob,@lunarway/squad"; got != want {
+		t.Errorf("RequestedReviewers = %q, want %q", got, want)
+	}
+	if got, want := strings.Join(pr.Labels, ","), "dependencies"; got != want {
+		t.Errorf("Labels = %q, want %q", got, want)
+	}
+	if pr.Diff != gitHubTestDiff {
+		t.Errorf("Diff = %q, want %q", pr.Diff, gitHubTestDiff)
+	}
+
+	wantComments := []string{"carol:Why 2?:2:main.go:old:2", "bob:Looks good::0", "alice:Because:2:main.go:old:2"}
+	if len(pr.Comments) != len(wantComments) {
+		t.Fatalf("got %d comments, want %d", len(pr.Comments), len(wantComments))
+	}
+	for i, comment := range pr.Comments {
+		got := fmt.Sprintf("%s:%s:%s:%d", comment.Author, comment.Body, comment.ThreadID, comment.Line)
+		if comment.Path != "" {
+			got = fmt.Sprintf("%s:%s:%s:%s:%s:%d", comment.Author, comment.Body, comment.ThreadID, comment.Path, comment.Side, comment.Line)
+		}
+		if got != wantComments[i] {
+			t.Errorf("comment %d = %q, want %q", i, got, wantComments[i])
+		}
+	}
+
+	wantChecks := []string{"build:completed:success", "test:completed:failure", "lint:in_progress:"}
+	if len(pr.Checks) != len(wantChecks) {
+		t.Fatalf("got %d checks, want %d", len(pr.Checks), len(wantChecks))
+	}
+	for i, check := range pr.Checks {
+		if got := fmt.Sprintf("%s:%s:%s", check.Name, check.Status, check.Conclusion); got != wantChecks[i] {
+			t.Errorf("check %d = %q, want %q", i, got, wantChecks[i])
+		}
+	}
+}
+
+func TestGitHubClientSubmitReview(t *testing.T) {
+	var got gitHubReview
+	client := newGitHubTestClient(t, map[string]http.HandlerFunc{
+		"POST /repos/lunarway/dr/pulls/7/reviews": func(w http.ResponseWriter, r *http.Request) {
+			if got := r.Header.Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+			if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+				t.Errorf("decoding review: %v", err)
+			}
+			writeJSON(t, w, map[string]any{"id": 1})
+		},
+	})
+
+	err := client.SubmitReview(context.Background(), PullRequestRef{Repo: "lunarway/dr", Number: 7}, "REQUEST_CHANGES", "Please fix", []ReviewComment{
+		{Path: "main.go", Line: 2, Side: DiffSideNew, Body: "use 3"},
+		{Path: "main.go", StartLine: 1, Line: 2, Side: DiffSideOld, Body: "keep this"},
+	})
+	if err != nil {
+		t.Fatalf("SubmitReview() error = %v", err)
+	}
+
+	want := gitHubReview{
+		Event: "REQUEST_CHANGES",
+		Body:  "Please fix",
+		Comments: []gitHubReviewComment{
+			{Path: "main.go", Line: 2, Side: "RIGHT", Body: "use 3"},
+			{Path: "main.go", StartLine: 1, StartSide: "LEFT", Line: 2, Side: "LEFT", Body: "keep this"},
+		},
+	}
+	if fmt.Sprintf("%+v", got) != fmt.Sprintf("%+v", want) {
+		t.Errorf("review = %+v, want %+v", got, want)
+	}
+}
+
+func TestGitHubClientErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		notFound bool
+		contains string
+	}{
+		{name: "not found", status: http.StatusNotFound, notFound: true},
+		{name: "unauthorized", status: http.StatusUnauthorized, contains: "401 Unauthorized: bad credentials"},
+		{name: "unprocessable", status: http.StatusUnprocessableEntity, contains: "422 Unprocessable Entity: bad credentials"},
+		{name: "server error", status: http.StatusInternalServerError, contains: "500 Internal Server Error: bad credentials"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := newGitHubTestClient(t, map[string]http.HandlerFunc{
+				"/": func(w http.ResponseWriter, r *http.Request) {
+					http.Error(w, "bad credentials", test.status)
+				},
+			})
+
+			_, err := client.GetPullRequest(context.Background(), PullRequestRef{Repo: "lunarway/dr", Number: 7})
+			if err == nil {
+				t.Fatal("GetPullRequest() error = nil")
+			}
+			if errors.Is(err, ErrNotFound) != test.notFound {
+				t.Errorf("errors.Is(%v, ErrNotFound) = %v, want %v", err, !test.notFound, test.notFound)
+			}
+			if !strings.Contains(err.Error(), test.contains) {
+				t.Errorf("error %q doesn't contain %q", err, test.contains)
+			}
+
+			err = client.SubmitReview(context.Background(), PullRequestRef{Repo: "lunarway/dr", Number: 7}, "APPROVE", "", nil)
+			if err == nil || !strings.Contains(err.Error(), test.contains) {
+				t.Errorf("SubmitReview() error = %v, want it to contain %q", err, test.contains)
+			}
+		})
+	}
+}
+
+func TestGitHubClientGetReviewers(t *testing.T) {
+	var serverURL string
+	client := newGitHubTestClient(t, map[string]http.HandlerFunc{
+		"GET /user": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(t, w, map[string]any{"login": "alice"})
+		},
+		"GET /user/teams": func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("page") == "2" {
+				writeJSON(t, w, []map[string]any{
+					{"slug": "squad-nasa", "organization": map[string]any{"login": "lunarway"}},
+				})
+				return
+			}
+
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v3/user/teams?per_page=100&page=2>; rel="next"`, serverURL))
+			writeJSON(t, w, []map[string]any{
+				{"slug": "squad-aura", "organization": map[string]any{"login": "lunarway"}},
+			})
+		},
+	})
+	serverURL = strings.TrimSuffix(client.baseURL, "/api/v3")
+
+	reviewers, err := client.GetReviewers(context.Background())
+	if err != nil {
+		t.Fatalf("GetReviewers() error = %v", err)
+	}
+
+	if got, want := strings.Join(reviewers, ","), "alice,@lunarway/squad-aura,@lunarway/squad-nasa"; got != want {
+		t.Errorf("GetReviewers() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,644 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultGitLabBaseURL = "https://gitlab.com/api/v4"
+
+type GitLabClientOption func(*GitLabClient)
+
+// WithGitLabBaseURL points the client at a self-hosted instance, either the
+// instance url (https://gitlab.example.com) or its api root.
+func WithGitLabBaseURL(baseURL string) GitLabClientOption {
+	return func(g *GitLabClient) {
+		baseURL = strings.TrimSuffix(baseURL, "/")
+		if !strings.HasSuffix(baseURL, "/api/v4") {
+			baseURL += "/api/v4"
+		}
+
+		g.baseURL = baseURL
+	}
+}
+
+func WithGitLabToken(token string) GitLabClientOption {
+	return func(g *GitLabClient) {
+		g.token = token
+	}
+}
+
+func WithGitLabHTTPClient(httpClient *http.Client) GitLabClientOption {
+	return func(g *GitLabClient) {
+		g.httpClient = httpClient
+	}
+}
+
+// GitLabClient is a minimal client for the parts of the GitLab REST API used
+// for reviewing merge requests.
+type GitLabClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+func NewGitLabClient(opts ...GitLabClientOption) *GitLabClient {
+	client := &GitLabClient{
+		baseURL:    DefaultGitLabBaseURL,
+		httpClient: http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client
+}
+
+type gitLabUser struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+}
+
+type gitLabMergeRequest struct {
+	IID          int          `json:"iid"`
+	WebURL       string       `json:"web_url"`
+	Title        string       `json:"title"`
+	Description  string       `json:"description"`
+	Author       gitLabUser   `json:"author"`
+	Labels       []string     `json:"labels"`
+	Draft        bool         `json:"draft"`
+	TargetBranch string       `json:"target_branch"`
+	SourceBranch string       `json:"source_branch"`
+	SHA          string       `json:"sha"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+	Reviewers    []gitLabUser `json:"reviewers"`
+	References   struct {
+		Full string `json:"full"`
+	} `json:"references"`
+	DiffRefs            gitLabDiffRefs `json:"diff_refs"`
+	DetailedMergeStatus string         `json:"detailed_merge_status"`
+}
+
+type gitLabDiffRefs struct {
+	BaseSHA  string `json:"base_sha"`
+	HeadSHA  string `json:"head_sha"`
+	StartSHA string `json:"start_sha"`
+}
+
+type gitLabDiscussion struct {
+	ID    string `json:"id"`
+	Notes []struct {
+		ID        int        `json:"id"`
+		Body      string     `json:"body"`
+		Author    gitLabUser `json:"author"`
+		CreatedAt time.Time  `json:"created_at"`
+		System    bool       `json:"system"`
+		Position  *struct {
+			NewPath string `json:"new_path"`
+			OldLine int    `json:"old_line"`
+			NewLine int    `json:"new_line"`
+		} `json:"position"`
+	} `json:"notes"`
+	IndividualNote bool `json:"individual_note"`
+}
+
+type gitLabApprovals struct {
+	ApprovedBy []struct {
+		User gitLabUser `json:"user"`
+	} `json:"approved_by"`
+}
+
+type gitLabPipeline struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+}
+
+type gitLabJob struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	WebURL string `json:"web_url"`
+}
+
+type gitLabDiff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	AMode       string `json:"a_mode"`
+	BMode       string `json:"b_mode"`
+	Diff        string `json:"diff"`
+	NewFile     bool   `json:"new_file"`
+	RenamedFile bool   `json:"renamed_file"`
+	DeletedFile bool   `json:"deleted_file"`
+}
+
+// ListReviewRequests returns the open merge requests where the authenticated
+// user is a reviewer.
+func (g *GitLabClient) ListReviewRequests(ctx context.Context) ([]PullRequestRef, error) {
+	var user gitLabUser
+	if _, err := g.getJSON(ctx, g.baseURL+"/user", &user); err != nil {
+		return nil, fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	refs := make([]PullRequestRef, 0)
+	next := g.baseURL + "/merge_requests?" + url.Values{
+		"state":       []string{"opened"},
+		"scope":       []string{"all"},
+		"reviewer_id": []string{fmt.Sprint(user.ID)},
+		"per_page":    []string{"100"},
+	}.Encode()
+
+	for next != "" {
+		var page []gitLabMergeRequest
+		link, err := g.getJSON(ctx, next, &page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list merge requests: %w", err)
+		}
+
+		for _, mr := range page {
+			repo, _, _ := strings.Cut(mr.References.Full, "!")
+			refs = append(refs, PullRequestRef{
+				Repo:   repo,
+				Number: mr.IID,
+			})
+		}
+
+		next = link
+	}
+
+	return refs, nil
+}
+
+// GetReviewers returns the authenticated user, GitLab only requests reviews
+// from users.
+func (g *GitLabClient) GetReviewers(ctx context.Context) ([]string, error) {
+	var user gitLabUser
+	if _, err := g.getJSON(ctx, g.baseURL+"/user", &user); err != nil {
+		return nil, fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	return []string{user.Username}, nil
+}
+
+// GetMergeRequest fetches the merge request along with its discussions, the
+// jobs of its latest pipeline and its diff.
+func (g *GitLabClient) GetMergeRequest(ctx context.Context, ref PullRequestRef) (*PullRequest, error) {
+	mrURL := g.mergeRequestURL(ref)
+
+	var mr gitLabMergeRequest
+	if _, err := g.getJSON(ctx, mrURL, &mr); err != nil {
+		return nil, fmt.Errorf("failed to get merge request %s: %w", ref, err)
+	}
+
+	comments := make([]Comment, 0)
+	next := mrURL + "/discussions?per_page=100"
+	for next != "" {
+		var page []gitLabDiscussion
+		link, err := g.getJSON(ctx, next, &page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get discussions for %s: %w", ref, err)
+		}
+
+		for _, discussion := range page {
+			for _, note := range discussion.Notes {
+				if note.System {
+					continue
+				}
+
+				comment := Comment{
+					ID:        strconv.Itoa(note.ID),
+					Author:    note.Author.Username,
+					Body:      note.Body,
+					CreatedAt: note.CreatedAt,
+					URL:       fmt.Sprintf("%s#note_%d", mr.WebURL, note.ID),
+				}
+				if !discussion.IndividualNote {
+					comment.ThreadID = discussion.ID
+				}
+				if note.Position != nil {
+					comment.Path = note.Position.NewPath
+					comment.Side, comment.Line = DiffSideNew, note.Position.NewLine
+					if note.Position.NewLine == 0 {
+						comment.Side, comment.Line = DiffSideOld, note.Position.OldLine
+					}
+				}
+
+				comments = append(comments, comment)
+			}
+		}
+
+		next = link
+	}
+
+	var approvals gitLabApprovals
+	if _, err := g.getJSON(ctx, mrURL+"/approvals", &approvals); err != nil {
+		return nil, fmt.Errorf("failed to get approvals for %s: %w", ref, err)
+	}
+
+	checks := make([]Check, 0)
+	var pipelines []gitLabPipeline
+	if _, err := g.getJSON(ctx, mrURL+"/pipelines", &pipelines); err != nil {
+		return nil, fmt.Errorf("failed to get pipelines for %s: %w", ref, err)
+	}
+	if len(pipelines) > 0 {
+		var jobs []gitLabJob
+		if _, err := g.getJSON(ctx, fmt.Sprintf("%s/pipelines/%d/jobs?per_page=100", g.projectURL(ref), pipelines[0].ID), &jobs); err != nil {
+			return nil, fmt.Errorf("failed to get jobs for %s: %w", ref, err)
+		}
+
+		for _, job := range jobs {
+			check := gitLabJobCheck(job.Status)
+			check.Name = job.Name
+			check.URL = job.WebURL
+
+			checks = append(checks, check)
+		}
+	}
+
+	var diff strings.Builder
+	next = mrURL + "/diffs?per_page=100"
+	for next != "" {
+		var page []gitLabDiff
+		link, err := g.getJSON(ctx, next, &page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get diff for %s: %w", ref, err)
+		}
+
+		for _, fileDiff := range page {
+			writeGitLabDiff(&diff, fileDiff)
+		}
+
+		next = link
+	}
+
+	requestedReviewers := make([]string, 0, len(mr.Reviewers))
+	for _, reviewer := range mr.Reviewers {
+		requestedReviewers = append(requestedReviewers, reviewer.Username)
+	}
+
+	return &PullRequest{
+		Ref:                ref,
+		URL:                mr.WebURL,
+		Title:              mr.Title,
+		Description:        mr.Description,
+		Author:             mr.Author.Username,
+		Labels:             mr.Labels,
+		Draft:              mr.Draft,
+		BaseRef:            mr.TargetBranch,
+		HeadRef:            mr.SourceBranch,
+		HeadSHA:            mr.SHA,
+		MergeState:         gitLabMergeState(mr.DetailedMergeStatus),
+		ReviewState:        gitLabReviewState(mr.DetailedMergeStatus, approvals),
+		CreatedAt:          mr.CreatedAt,
+		UpdatedAt:          mr.UpdatedAt,
+		RequestedReviewers: requestedReviewers,
+		Comments:           comments,
+		Checks:             checks,
+		Files:              parseFiles(diff.String()),
+		Diff:               diff.String(),
+	}, nil
+}
+
+// gitLabMergeState maps the detailed merge status of a merge request, every
+// status not listed is a rule blocking the merge.
+func gitLabMergeState(status string) MergeState {
+	switch status {
+	case "mergeable":
+		return MergeStateClean
+	case "conflict", "broken_status":
+		return MergeStateConflicting
+	case "need_rebase":
+		return MergeStateBehind
+	case "", "unchecked", "checking", "preparing", "approvals_syncing":
+		return MergeStateUnknown
+	default:
+		return MergeStateBlocked
+	}
+}
+
+// gitLabReviewState derives the review state from the approvals, GitLab only
+// reports requested changes through the merge status.
+func gitLabReviewState(status string, approvals gitLabApprovals) ReviewState {
+	switch {
+	case status == "requested_changes":
+		return ReviewStateChangesRequested
+	case len(approvals.ApprovedBy) > 0:
+		return ReviewStateApproved
+	default:
+		return ReviewStatePending
+	}
+}
+
+// gitLabJobCheck maps the status of a GitLab job onto a check.
+func gitLabJobCheck(status string) Check {
+	switch status {
+	case "created", "waiting_for_resource", "preparing", "pending", "scheduled":
+		return Check{Status: CheckStatusQueued}
+	case "running":
+		return Check{Status: CheckStatusInProgress}
+	case "success":
+		return Check{Status: CheckStatusCompleted, Conclusion: CheckConclusionSuccess}
+	case "failed":
+		return Check{Status: CheckStatusCompleted, Conclusion: CheckConclusionFailure}
+	case "canceled":
+		return Check{Status: CheckStatusCompleted, Conclusion: CheckConclusionCancelled}
+	case "skipped", "manual":
+		return Check{Status: CheckStatusCompleted, Conclusion: CheckConclusionSkipped}
+	default:
+		return Check{Status: CheckStatusCompleted, Conclusion: CheckConclusionNeutral}
+	}
+}
+
+// writeGitLabDiff restores the git headers GitLab strips from file diffs, so
+// the result reads like the output of git diff.
+func writeGitLabDiff(w *strings.Builder, d gitLabDiff) {
+	fmt.Fprintf(w, "diff --git a/%s b/%s\n", d.OldPath, d.NewPath)
+
+	oldPath, newPath := "a/"+d.OldPath, "b/"+d.NewPath
+	switch {
+	case d.NewFile:
+		fmt.Fprintf(w, "new file mode %s\n", d.BMode)
+		oldPath = "/dev/null"
+	case d.DeletedFile:
+		fmt.Fprintf(w, "deleted file mode %s\n", d.AMode)
+		newPath = "/dev/null"
+	default:
+		if d.AMode != d.BMode {
+			fmt.Fprintf(w, "old mode %s\nnew mode %s\n", d.AMode, d.BMode)
+		}
+		if d.RenamedFile {
+			fmt.Fprintf(w, "rename from %s\nrename to %s\n", d.OldPath, d.NewPath)
+		}
+	}
+
+	if d.Diff == "" {
+		return
+	}
+
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", oldPath, newPath)
+	w.WriteString(d.Diff)
+	if !strings.HasSuffix(d.Diff, "\n") {
+		w.WriteString("\n")
+	}
+}
+
+// GetCodeOwners returns the CODEOWNERS file on the default branch of the
+// project.
+func (g *GitLabClient) GetCodeOwners(ctx context.Context, repo string) (string, error) {
+	projectURL := g.projectURL(PullRequestRef{Repo: repo})
+
+	for _, path := range codeOwnersPaths(".gitlab") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, projectURL+"/repository/files/"+url.PathEscape(path)+"/raw?ref=HEAD", nil)
+		if err != nil {
+			return "", err
+		}
+
+		resp, err := g.do(req)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to get CODEOWNERS for %s: %w", repo, err)
+		}
+
+		return string(resp.body), nil
+	}
+
+	return "", nil
+}
+
+func (g *GitLabClient) Approve(ctx context.Context, ref PullRequestRef) error {
+	if _, err := g.send(ctx, http.MethodPost, g.mergeRequestURL(ref)+"/approve", struct{}{}); err != nil {
+		return fmt.Errorf("failed to approve %s: %w", ref, err)
+	}
+
+	return nil
+}
+
+// HasApproved reports whether the authenticated user has approved the merge
+// request.
+func (g *GitLabClient) HasApproved(ctx context.Context, ref PullRequestRef) (bool, error) {
+	var user gitLabUser
+	if _, err := g.getJSON(ctx, g.baseURL+"/user", &user); err != nil {
+		return false, fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	var approvals gitLabApprovals
+	if _, err := g.getJSON(ctx, g.mergeRequestURL(ref)+"/approvals", &approvals); err != nil {
+		return false, fmt.Errorf("failed to get approvals for %s: %w", ref, err)
+	}
+
+	for _, approval := range approvals.ApprovedBy {
+		if approval.User.ID == user.ID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (g *GitLabClient) Unapprove(ctx context.Context, ref PullRequestRef) error {
+	if _, err := g.send(ctx, http.MethodPost, g.mergeRequestURL(ref)+"/unapprove", struct{}{}); err != nil {
+		return fmt.Errorf("failed to unapprove %s: %w", ref, err)
+	}
+
+	return nil
+}
+
+type gitLabMerge struct {
+	Squash                    bool `json:"squash,omitempty"`
+	MergeWhenPipelineSucceeds bool `json:"merge_when_pipeline_succeeds,omitempty"`
+}
+
+// Merge merges the merge request, or sets it to merge when its pipeline
+// succeeds. GitLab rebases or not according to the project settings, so only
+// merge and squash are supported.
+func (g *GitLabClient) Merge(ctx context.Context, ref PullRequestRef, method MergeMethod, whenPipelineSucceeds bool) error {
+	merge := gitLabMerge{MergeWhenPipelineSucceeds: whenPipelineSucceeds}
+	switch method {
+	case MergeMethodMerge:
+	case MergeMethodSquash:
+		merge.Squash = true
+	default:
+		return fmt.Errorf("unsupported merge method for gitlab: %s", method)
+	}
+
+	if _, err := g.send(ctx, http.MethodPut, g.mergeRequestURL(ref)+"/merge", merge); err != nil {
+		return fmt.Errorf("failed to merge %s: %w", ref, err)
+	}
+
+	return nil
+}
+
+func (g *GitLabClient) Close(ctx context.Context, ref PullRequestRef) error {
+	update := struct {
+		StateEvent string `json:"state_event"`
+	}{
+		StateEvent: "close",
+	}
+	if _, err := g.send(ctx, http.MethodPut, g.mergeRequestURL(ref), update); err != nil {
+		return fmt.Errorf("failed to close %s: %w", ref, err)
+	}
+
+	return nil
+}
+
+func (g *GitLabClient) CreateNote(ctx context.Context, ref PullRequestRef, body string) error {
+	note := struct {
+		Body string `json:"body"`
+	}{
+		Body: body,
+	}
+	if _, err := g.send(ctx, http.MethodPost, g.mergeRequestURL(ref)+"/notes", note); err != nil {
+		return fmt.Errorf("failed to comment on %s: %w", ref, err)
+	}
+
+	return nil
+}
+
+type gitLabPosition struct {
+	PositionType string `json:"position_type"`
+	BaseSHA      string `json:"base_sha"`
+	HeadSHA      string `json:"head_sha"`
+	StartSHA     string `json:"start_sha"`
+	OldPath      string `json:"old_path"`
+	NewPath      string `json:"new_path"`
+	OldLine      int    `json:"old_line,omitempty"`
+	NewLine      int    `json:"new_line,omitempty"`
+}
+
+// CreateDiscussions starts a discussion on the diff of the merge request for
+// each of the comments.
+func (g *GitLabClient) CreateDiscussions(ctx context.Context, ref PullRequestRef, comments []ReviewComment) error {
+	mrURL := g.mergeRequestURL(ref)
+
+	var mr gitLabMergeRequest
+	if _, err := g.getJSON(ctx, mrURL, &mr); err != nil {
+		return fmt.Errorf("failed to get merge request %s: %w", ref, err)
+	}
+
+	for _, comment := range comments {
+		position := gitLabPosition{
+			PositionType: "text",
+			BaseSHA:      mr.DiffRefs.BaseSHA,
+			HeadSHA:      mr.DiffRefs.HeadSHA,
+			StartSHA:     mr.DiffRefs.StartSHA,
+			OldPath:      comment.OldPath,
+			NewPath:      comment.Path,
+		}
+		if position.OldPath == "" {
+			position.OldPath = comment.Path
+		}
+		// Unchanged lines are placed by their line on both sides.
+		if comment.Side == DiffSideOld {
+			position.OldLine = comment.Line
+		} else {
+			position.OldLine, position.NewLine = comment.OldLine, comment.Line
+		}
+
+		body := comment.Body
+		if comment.StartLine > 0 && comment.StartLine < comment.Line {
+			// GitLab places comments on a single line, suggestions reach back
+			// over the lines above it instead.
+			body = strings.ReplaceAll(body, "```suggestion\n", fmt.Sprintf("```suggestion:-%d+0\n", comment.Line-comment.StartLine))
+		}
+
+		discussion := struct {
+			Body     string         `json:"body"`
+			Position gitLabPosition `json:"position"`
+		}{
+			Body:     body,
+			Position: position,
+		}
+		if _, err := g.send(ctx, http.MethodPost, mrURL+"/discussions", discussion); err != nil {
+			return fmt.Errorf("failed to comment on %s:%d in %s: %w", comment.Path, comment.Line, ref, err)
+		}
+	}
+
+	return nil
+}
+
+func (g *GitLabClient) projectURL(ref PullRequestRef) string {
+	return fmt.Sprintf("%s/projects/%s", g.baseURL, url.PathEscape(ref.Repo))
+}
+
+func (g *GitLabClient) mergeRequestURL(ref PullRequestRef) string {
+	return fmt.Sprintf("%s/merge_requests/%d", g.projectURL(ref), ref.Number)
+}
+
+type gitLabResponse struct {
+	body []byte
+	next string
+}
+
+func (g *GitLabClient) getJSON(ctx context.Context, endpoint string, out any) (next string, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := g.do(req)
+	if err != nil {
+		return "", err
+	}
+
+	if err := json.Unmarshal(resp.body, out); err != nil {
+		return "", fmt.Errorf("failed to decode response from %s: %w", endpoint, err)
+	}
+
+	return resp.next, nil
+}
+
+func (g *GitLabClient) send(ctx context.Context, method string, endpoint string, in any) (*gitLabResponse, error) {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	return g.do(req)
+}
+
+func (g *GitLabClient) do(req *http.Request) (*gitLabResponse, error) {
+	if g.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", g.token)
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("gitlab: %s %s: %w", req.Method, req.URL, ErrNotFound)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("gitlab: %s %s returned %s: %s", req.Method, req.URL, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return &gitLabResponse{
+		body: body,
+		next: parseNextLink(resp.Header.Get("Link")),
+	}, nil
+}
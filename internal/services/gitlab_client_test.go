@@ -0,0 +1,443 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// gitLabFixture is a response recorded from a GitLab instance, served from
+// testdata/gitlab. next is the page linked as the following one.
+type gitLabFixture struct {
+	status int
+	file   string
+	next   string
+}
+
+type gitLabRequest struct {
+	method string
+	path   string
+	body   string
+}
+
+// newGitLabFixtureServer serves the fixtures by method and escaped path, with
+// ?page=N appended for later pages, and records the requests made.
+func newGitLabFixtureServer(t *testing.T, fixtures map[string]gitLabFixture) (*GitLabClient, func() []gitLabRequest) {
+	t.Helper()
+
+	var (
+		mu       sync.Mutex
+		requests []gitLabRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("PRIVATE-TOKEN"); got != "token" {
+			t.Errorf("PRIVATE-TOKEN = %q, want %q", got, "token")
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		path := strings.TrimPrefix(r.URL.EscapedPath(), "/api/v4")
+		mu.Lock()
+		requests = append(requests, gitLabRequest{method: r.Method, path: path, body: string(body)})
+		mu.Unlock()
+
+		key := r.Method + " " + path
+		if page := r.URL.Query().Get("page"); page != "" {
+			key += "?page=" + page
+		}
+		fixture, ok := fixtures[key]
+		if !ok {
+			http.Error(w, `{"message":"404 Not found"}`, http.StatusNotFound)
+			return
+		}
+
+		content, err := os.ReadFile(filepath.Join("testdata", "gitlab", fixture.file))
+		if err != nil {
+			t.Errorf("reading fixture: %v", err)
+		}
+		if fixture.next != "" {
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?page=%s&per_page=100>; rel="next"`, r.Host, r.URL.Path, fixture.next))
+		}
+		if fixture.status != 0 {
+			w.WriteHeader(fixture.status)
+		}
+		w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewGitLabClient(
+		WithGitLabBaseURL(server.URL),
+		WithGitLabToken("token"),
+		WithGitLabHTTPClient(server.Client()),
+	)
+
+	return client, func() []gitLabRequest {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]gitLabRequest(nil), requests...)
+	}
+}
+
+const gitLabTestProject = "/projects/platform%2Fdr"
+
+var gitLabTestRef = PullRequestRef{Repo: "platform/dr", Number: 7}
+
+func TestGitLabClientListReviewRequests(t *testing.T) {
+	client, requests := newGitLabFixtureServer(t, map[string]gitLabFixture{
+		"GET /user":                  {file: "user.json"},
+		"GET /merge_requests":        {file: "merge_requests.json", next: "2"},
+		"GET /merge_requests?page=2": {file: "merge_requests_page_2.json"},
+	})
+
+	refs, err := client.ListReviewRequests(context.Background())
+	if err != nil {
+		t.Fatalf("ListReviewRequests() error = %v", err)
+	}
+
+	want := []PullRequestRef{
+		{Repo: "platform/dr", Number: 7},
+		{Repo: "platform/tools/shuttle", Number: 12},
+		{Repo: "platform/dr", Number: 8},
+	}
+	if fmt.Sprint(refs) != fmt.Sprint(want) {
+		t.Errorf("ListReviewRequests() = %v, want %v", refs, want)
+	}
+
+	if got := requests(); len(got) != 3 {
+		t.Errorf("made %d requests, want 3", len(got))
+	}
+}
+
+func TestGitLabClientListReviewRequestsFiltersByReviewer(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/user":
+			fmt.Fprint(w, `{"id": 42}`)
+		default:
+			query = r.URL.RawQuery
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewGitLabClient(WithGitLabBaseURL(server.URL + "/api/v4/"))
+	if _, err := client.ListReviewRequests(context.Background()); err != nil {
+		t.Fatalf("ListReviewRequests() error = %v", err)
+	}
+
+	for _, param := range []string{"state=opened", "scope=all", "reviewer_id=42"} {
+		if !strings.Contains(query, param) {
+			t.Errorf("query %q doesn't contain %q", query, param)
+		}
+	}
+}
+
+func TestGitLabClientGetMergeRequest(t *testing.T) {
+	client, _ := newGitLabFixtureServer(t, map[string]gitLabFixture{
+		"GET " + gitLabTestProject + "/merge_requests/7":             {file: "merge_request.json"},
+		"GET " + gitLabTestProject + "/merge_requests/7/discussions": {file: "discussions.json"},
+		"GET " + gitLabTestProject + "/merge_requests/7/approvals":   {file: "approvals.json"},
+		"GET " + gitLabTestProject + "/merge_requests/7/pipelines":   {file: "pipelines.json"},
+		"GET " + gitLabTestProject + "/pipelines/77/jobs":            {file: "jobs.json"},
+		"GET " + gitLabTestProject + "/merge_requests/7/diffs":       {file: "diffs.json"},
+	})
+
+	pr, err := client.GetMergeRequest(context.Background(), gitLabTestRef)
+	if err != nil {
+		t.Fatalf("GetMergeRequest() error = %v", err)
+	}
+
+	if pr.Title != "Bump x" || pr.Description != "Bumps x to 2." || pr.Author != "bob" {
+		t.Errorf("title, description, author = %q, %q, %q", pr.Title, pr.Description, pr.Author)
+	}
+	if pr.URL != "https://gitlab.example.com/platform/dr/-/merge_requests/7" {
+		t.Errorf("URL = %q", pr.URL)
+	}
+	if pr.BaseRef != "main" || pr.HeadRef != "bump-x" || pr.HeadSHA != "abc123" {
+		t.Errorf("refs = %q, %q, %q", pr.BaseRef, pr.HeadRef, pr.HeadSHA)
+	}
+	if pr.MergeState != MergeStateBehind {
+		t.Errorf("MergeState = %q, want %q", pr.MergeState, MergeStateBehind)
+	}
+	if pr.ReviewState != ReviewStateApproved {
+		t.Errorf("ReviewState = %q, want %q", pr.ReviewState, ReviewStateApproved)
+	}
+	if got, want := strings.Join(pr.RequestedReviewers, ","), "alice,carol"; got != want {
+		t.Errorf("RequestedReviewers = %q, want %q", got, want)
+	}
+	if got, want := strings.Join(pr.Labels, ","), "dependencies"; got != want {
+		t.Errorf("Labels = %q, want %q", got, want)
+	}
+
+	wantComments := []string{
+		"1:carol:Looks good::::0",
+		"2:carol:Why 2?:87805b7c09016a7058e91bdbe7b29d1f284a39e6:main.go:new:2",
+		"3:bob:Because:87805b7c09016a7058e91bdbe7b29d1f284a39e6:main.go:new:2",
+		"5:bob:Why drop this?:9b8a7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b:main.go:old:2",
+	}
+	if len(pr.Comments) != len(wantComments) {
+		t.Fatalf("got %d comments, want %d", len(pr.Comments), len(wantComments))
+	}
+	for i, comment := range pr.Comments {
+		if got := fmt.Sprintf("%s:%s:%s:%s:%s:%s:%d", comment.ID, comment.Author, comment.Body, comment.ThreadID, comment.Path, comment.Side, comment.Line); got != wantComments[i] {
+			t.Errorf("comment %d = %q, want %q", i, got, wantComments[i])
+		}
+	}
+	if got, want := pr.Comments[0].URL, pr.URL+"#note_1"; got != want {
+		t.Errorf("comment URL = %q, want %q", got, want)
+	}
+
+	wantChecks := []string{
+		"build:completed:success",
+		"test:completed:failure",
+		"deploy:completed:skipped",
+		"lint:in_progress:",
+	}
+	if len(pr.Checks) != len(wantChecks) {
+		t.Fatalf("got %d checks, want %d", len(pr.Checks), len(wantChecks))
+	}
+	for i, check := range pr.Checks {
+		if got := fmt.Sprintf("%s:%s:%s", check.Name, check.Status, check.Conclusion); got != wantChecks[i] {
+			t.Errorf("check %d = %q, want %q", i, got, wantChecks[i])
+		}
+	}
+
+	wantDiff := `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,2 +1,2 @@
+ package main
+-var x = 1
++var x = 2
+diff --git a/docs/old.md b/docs/new.md
+rename from docs/old.md
+rename to docs/new.md
+diff --git a/run.sh b/run.sh
+new file mode 100755
+--- /dev/null
++++ b/run.sh
+@@ -0,0 +1 @@
++echo hi
+`
+	if pr.Diff != wantDiff {
+		t.Errorf("Diff = %q, want %q", pr.Diff, wantDiff)
+	}
+	if len(pr.Files) != 3 {
+		t.Errorf("got %d files, want 3", len(pr.Files))
+	}
+}
+
+func TestGitLabClientGetCodeOwners(t *testing.T) {
+	client, requests := newGitLabFixtureServer(t, map[string]gitLabFixture{
+		"GET " + gitLabTestProject + "/repository/files/CODEOWNERS/raw": {file: "CODEOWNERS"},
+	})
+
+	codeOwners, err := client.GetCodeOwners(context.Background(), "platform/dr")
+	if err != nil {
+		t.Fatalf("GetCodeOwners() error = %v", err)
+	}
+	if !strings.Contains(codeOwners, "* @platform/squad") {
+		t.Errorf("GetCodeOwners() = %q", codeOwners)
+	}
+
+	// .gitlab/CODEOWNERS is looked for first.
+	got := requests()
+	if len(got) != 2 || got[0].path != gitLabTestProject+"/repository/files/.gitlab%2FCODEOWNERS/raw" {
+		t.Errorf("requests = %+v", got)
+	}
+}
+
+func TestGitLabClientGetCodeOwnersMissing(t *testing.T) {
+	client, _ := newGitLabFixtureServer(t, map[string]gitLabFixture{})
+
+	codeOwners, err := client.GetCodeOwners(context.Background(), "platform/dr")
+	if err != nil || codeOwners != "" {
+		t.Errorf("GetCodeOwners() = %q, %v, want no CODEOWNERS", codeOwners, err)
+	}
+}
+
+func TestGitLabClientActions(t *testing.T) {
+	mrPath := gitLabTestProject + "/merge_requests/7"
+
+	tests := []struct {
+		name string
+		act  func(client *GitLabClient) error
+		// want is the request made, as method, path and body.
+		want gitLabRequest
+	}{
+		{
+			name: "approve",
+			act:  func(client *GitLabClient) error { return client.Approve(context.Background(), gitLabTestRef) },
+			want: gitLabRequest{method: http.MethodPost, path: mrPath + "/approve", body: `{}`},
+		},
+		{
+			name: "unapprove",
+			act:  func(client *GitLabClient) error { return client.Unapprove(context.Background(), gitLabTestRef) },
+			want: gitLabRequest{method: http.MethodPost, path: mrPath + "/unapprove", body: `{}`},
+		},
+		{
+			name: "merge",
+			act: func(client *GitLabClient) error {
+				return client.Merge(context.Background(), gitLabTestRef, MergeMethodMerge, false)
+			},
+			want: gitLabRequest{method: http.MethodPut, path: mrPath + "/merge", body: `{}`},
+		},
+		{
+			name: "squash when pipeline succeeds",
+			act: func(client *GitLabClient) error {
+				return client.Merge(context.Background(), gitLabTestRef, MergeMethodSquash, true)
+			},
+			want: gitLabRequest{method: http.MethodPut, path: mrPath + "/merge", body: `{"squash":true,"merge_when_pipeline_succeeds":true}`},
+		},
+		{
+			name: "close",
+			act:  func(client *GitLabClient) error { return client.Close(context.Background(), gitLabTestRef) },
+			want: gitLabRequest{method: http.MethodPut, path: mrPath, body: `{"state_event":"close"}`},
+		},
+		{
+			name: "note",
+			act: func(client *GitLabClient) error {
+				return client.CreateNote(context.Background(), gitLabTestRef, "Thanks!")
+			},
+			want: gitLabRequest{method: http.MethodPost, path: mrPath + "/notes", body: `{"body":"Thanks!"}`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, requests := newGitLabFixtureServer(t, map[string]gitLabFixture{
+				"POST " + mrPath + "/approve":   {file: "approve.json"},
+				"POST " + mrPath + "/unapprove": {file: "approve.json"},
+				"PUT " + mrPath + "/merge":      {file: "merge.json"},
+				"PUT " + mrPath:                 {file: "close.json"},
+				"POST " + mrPath + "/notes":     {status: http.StatusCreated, file: "note.json"},
+			})
+
+			if err := test.act(client); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+
+			got := requests()
+			if len(got) != 1 || got[0] != test.want {
+				t.Errorf("requests = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGitLabClientMergeRebase(t *testing.T) {
+	client, requests := newGitLabFixtureServer(t, map[string]gitLabFixture{})
+
+	if err := client.Merge(context.Background(), gitLabTestRef, MergeMethodRebase, false); err == nil {
+		t.Error("Merge() error = nil, want rebase to be unsupported")
+	}
+	if got := requests(); len(got) != 0 {
+		t.Errorf("requests = %+v, want none", got)
+	}
+}
+
+func TestGitLabClientCreateDiscussions(t *testing.T) {
+	mrPath := gitLabTestProject + "/merge_requests/7"
+	client, requests := newGitLabFixtureServer(t, map[string]gitLabFixture{
+		"GET " + mrPath:                   {file: "merge_request.json"},
+		"POST " + mrPath + "/discussions": {status: http.StatusCreated, file: "discussion.json"},
+	})
+
+	err := client.CreateDiscussions(context.Background(), gitLabTestRef, []ReviewComment{
+		{Path: "main.go", Line: 2, Side: DiffSideNew, Body: "use 3"},
+		{Path: "main.go", Line: 2, Side: DiffSideOld, Body: "keep this"},
+		{Path: "main.go", StartLine: 1, Line: 2, Side: DiffSideNew, Body: "```suggestion\nvar x = 3\n```"},
+		{Path: "cmd/main.go", OldPath: "main.go", Line: 5, OldLine: 4, Side: DiffSideNew, Body: "unchanged"},
+	})
+	if err != nil {
+		t.Fatalf("CreateDiscussions() error = %v", err)
+	}
+
+	type discussion struct {
+		Body     string         `json:"body"`
+		Position gitLabPosition `json:"position"`
+	}
+	position := gitLabPosition{
+		PositionType: "text",
+		BaseSHA:      "base000",
+		HeadSHA:      "abc123",
+		StartSHA:     "start000",
+		OldPath:      "main.go",
+		NewPath:      "main.go",
+	}
+	withLines := func(oldLine, newLine int) gitLabPosition {
+		position := position
+		position.OldLine, position.NewLine = oldLine, newLine
+		return position
+	}
+	renamed := withLines(4, 5)
+	renamed.NewPath = "cmd/main.go"
+	want := []discussion{
+		{Body: "use 3", Position: withLines(0, 2)},
+		{Body: "keep this", Position: withLines(2, 0)},
+		{Body: "```suggestion:-1+0\nvar x = 3\n```", Position: withLines(0, 2)},
+		{Body: "unchanged", Position: renamed},
+	}
+
+	var got []discussion
+	for _, request := range requests() {
+		if request.method != http.MethodPost {
+			continue
+		}
+		var d discussion
+		if err := json.Unmarshal([]byte(request.body), &d); err != nil {
+			t.Fatalf("decoding discussion: %v", err)
+		}
+		got = append(got, d)
+	}
+	if fmt.Sprintf("%+v", got) != fmt.Sprintf("%+v", want) {
+		t.Errorf("discussions = %+v, want %+v", got, want)
+	}
+}
+
+func TestGitLabClientErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		notFound bool
+		contains string
+	}{
+		{name: "not found", status: http.StatusNotFound, notFound: true},
+		{name: "unauthorized", status: http.StatusUnauthorized, contains: "401 Unauthorized"},
+		{name: "server error", status: http.StatusInternalServerError, contains: "500 Internal Server Error"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, _ := newGitLabFixtureServer(t, map[string]gitLabFixture{
+				"GET " + gitLabTestProject + "/merge_requests/7":          {status: test.status, file: "merge_request.json"},
+				"POST " + gitLabTestProject + "/merge_requests/7/approve": {status: test.status, file: "approve.json"},
+			})
+
+			_, err := client.GetMergeRequest(context.Background(), gitLabTestRef)
+			if err == nil {
+				t.Fatal("GetMergeRequest() error = nil")
+			}
+			if errors.Is(err, ErrNotFound) != test.notFound {
+				t.Errorf("errors.Is(%v, ErrNotFound) = %v, want %v", err, !test.notFound, test.notFound)
+			}
+			if !strings.Contains(err.Error(), test.contains) {
+				t.Errorf("error %q doesn't contain %q", err, test.contains)
+			}
+
+			if err := client.Approve(context.Background(), gitLabTestRef); err == nil || !strings.Contains(err.Error(), test.contains) {
+				t.Errorf("Approve() error = %v, want it to contain %q", err, test.contains)
+			}
+		})
+	}
+}
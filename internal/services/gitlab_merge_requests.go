@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"fmt"
+)
+
+// GitLabMergeRequestProvider reviews merge requests on gitlab.com or a
+// self-hosted GitLab instance.
+type GitLabMergeRequestProvider struct {
+	client *GitLabClient
+	queue  pullRequestQueue
+}
+
+func NewGitLabMergeRequestProvider(client *GitLabClient) *GitLabMergeRequestProvider {
+	return &GitLabMergeRequestProvider{
+		client: client,
+	}
+}
+
+func (g *GitLabMergeRequestProvider) List(ctx context.Context) ([]PullRequestRef, error) {
+	return g.client.ListReviewRequests(ctx)
+}
+
+func (g *GitLabMergeRequestProvider) Get(ctx context.Context, ref PullRequestRef) (*PullRequest, error) {
+	return g.client.GetMergeRequest(ctx, ref)
+}
+
+func (g *GitLabMergeRequestProvider) GetCodeOwners(ctx context.Context, repo string) (string, error) {
+	return g.client.GetCodeOwners(ctx, repo)
+}
+
+func (g *GitLabMergeRequestProvider) GetReviewers(ctx context.Context) ([]string, error) {
+	return g.client.GetReviewers(ctx)
+}
+
+func (g *GitLabMergeRequestProvider) GetNext(ctx context.Context) (*PullRequest, bool, error) {
+	return g.queue.next(ctx, g.List, g.Get)
+}
+
+// Act maps review actions onto GitLab, which has no review verdicts: approve
+// approves, request changes revokes our approval, line comments start diff
+// discussions and any body is left as a note.
+func (g *GitLabMergeRequestProvider) Act(ctx context.Context, ref PullRequestRef, action PullRequestAction) error {
+	switch action.Kind {
+	case PullRequestActionMerge:
+		return g.client.Merge(ctx, ref, action.MergeMethod, false)
+	case PullRequestActionAutoMerge:
+		return g.client.Merge(ctx, ref, action.MergeMethod, true)
+	case PullRequestActionClose:
+		return g.client.Close(ctx, ref)
+	case PullRequestActionApprove:
+		if err := g.client.Approve(ctx, ref); err != nil {
+			return err
+		}
+	case PullRequestActionRequestChanges, PullRequestActionComment:
+	default:
+		return fmt.Errorf("unsupported action: %s", action.Kind)
+	}
+
+	if len(action.Comments) > 0 {
+		if err := g.client.CreateDiscussions(ctx, ref, action.Comments); err != nil {
+			return err
+		}
+	}
+
+	if action.Body != "" {
+		if err := g.client.CreateNote(ctx, ref, action.Body); err != nil {
+			return err
+		}
+	}
+
+	if action.Kind != PullRequestActionRequestChanges {
+		return nil
+	}
+
+	// The review is posted before revoking the approval, GitLab refuses to
+	// unapprove a merge request we haven't approved.
+	approved, err := g.client.HasApproved(ctx, ref)
+	if err != nil || !approved {
+		return err
+	}
+
+	return g.client.Unapprove(ctx, ref)
+}
+
+var _ PullRequestProvider = &GitLabMergeRequestProvider{}
+var _ CodeOwnersReader = &GitLabMergeRequestProvider{}
+var _ ReviewersReader = &GitLabMergeRequestProvider{}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGitLabMergeRequestProviderRequestChanges(t *testing.T) {
+	mrPath := gitLabTestProject + "/merge_requests/7"
+
+	tests := []struct {
+		name      string
+		approvals string
+		// want is the requests made, as method and path.
+		want []string
+	}{
+		{
+			name:      "not approved",
+			approvals: "approvals.json",
+			want: []string{
+				"POST " + mrPath + "/notes",
+				"GET /user",
+				"GET " + mrPath + "/approvals",
+			},
+		},
+		{
+			name:      "approved",
+			approvals: "approvals_approved.json",
+			want: []string{
+				"POST " + mrPath + "/notes",
+				"GET /user",
+				"GET " + mrPath + "/approvals",
+				"POST " + mrPath + "/unapprove",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, requests := newGitLabFixtureServer(t, map[string]gitLabFixture{
+				"GET /user":                     {file: "user.json"},
+				"GET " + mrPath + "/approvals":  {file: test.approvals},
+				"POST " + mrPath + "/notes":     {status: http.StatusCreated, file: "note.json"},
+				"POST " + mrPath + "/unapprove": {file: "approve.json"},
+			})
+			provider := NewGitLabMergeRequestProvider(client)
+
+			err := provider.Act(context.Background(), gitLabTestRef, PullRequestAction{
+				Kind: PullRequestActionRequestChanges,
+				Body: "Please fix",
+			})
+			if err != nil {
+				t.Fatalf("Act() error = %v", err)
+			}
+
+			var got []string
+			for _, request := range requests() {
+				got = append(got, request.method+" "+request.path)
+			}
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("requests = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGitLabMergeRequestProviderCommentsOnRenamedFiles(t *testing.T) {
+	mrPath := gitLabTestProject + "/merge_requests/7"
+	client, requests := newGitLabFixtureServer(t, map[string]gitLabFixture{
+		"GET " + mrPath:                   {file: "merge_request.json"},
+		"POST " + mrPath + "/discussions": {status: http.StatusCreated, file: "discussion.json"},
+	})
+	provider := NewGitLabMergeRequestProvider(client)
+
+	// The comments as the review page places them on the lines of a renamed
+	// and a deleted file.
+	err := provider.Act(context.Background(), gitLabTestRef, PullRequestAction{
+		Kind: PullRequestActionComment,
+		Comments: []ReviewComment{
+			{Path: "cmd/main.go", OldPath: "main.go", Line: 2, Side: DiffSideOld, Body: "removed"},
+			{Path: "cmd/main.go", OldPath: "main.go", Line: 5, OldLine: 4, Side: DiffSideNew, Body: "context"},
+			{Path: "cmd/main.go", OldPath: "main.go", Line: 3, Side: DiffSideNew, Body: "added"},
+			{Path: "old.go", OldPath: "old.go", Line: 1, Side: DiffSideOld, Body: "deleted"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Act() error = %v", err)
+	}
+
+	want := []string{
+		"removed main.go:2 cmd/main.go:0",
+		"context main.go:4 cmd/main.go:5",
+		"added main.go:0 cmd/main.go:3",
+		"deleted old.go:1 old.go:0",
+	}
+	var got []string
+	for _, request := range requests() {
+		if request.method != http.MethodPost {
+			continue
+		}
+		var discussion struct {
+			Body     string         `json:"body"`
+			Position gitLabPosition `json:"position"`
+		}
+		if err := json.Unmarshal([]byte(request.body), &discussion); err != nil {
+			t.Fatalf("decoding discussion: %v", err)
+		}
+		position := discussion.Position
+		got = append(got, fmt.Sprintf("%s %s:%d %s:%d", discussion.Body, position.OldPath, position.OldLine, position.NewPath, position.NewLine))
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("discussions = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrNotFound = errors.New("not found")
+
+type PullRequestActionKind string
+
+const (
+	PullRequestActionApprove        PullRequestActionKind = "approve"
+	PullRequestActionRequestChanges PullRequestActionKind = "request_changes"
+	PullRequestActionComment        PullRequestActionKind = "comment"
+	PullRequestActionMerge          PullRequestActionKind = "merge"
+	// PullRequestActionAutoMerge merges the pull request once its required
+	// checks pass.
+	PullRequestActionAutoMerge PullRequestActionKind = "auto_merge"
+	PullRequestActionClose     PullRequestActionKind = "close"
+)
+
+type MergeMethod string
+
+const (
+	MergeMethodMerge  MergeMethod = "merge"
+	MergeMethodSquash MergeMethod = "squash"
+	MergeMethodRebase MergeMethod = "rebase"
+)
+
+var MergeMethods = []MergeMethod{
+	MergeMethodMerge,
+	MergeMethodSquash,
+	MergeMethodRebase,
+}
+
+type PullRequestAction struct {
+	Kind PullRequestActionKind
+	Body string
+	// MergeMethod is how merge and auto merge actions merge the pull request.
+	MergeMethod MergeMethod
+	// Comments are line comments submitted together with the review.
+	Comments []ReviewComment
+}
+
+// DiffSide is the side of the diff a line comment is placed on, old for
+// removed lines and new for added and unchanged lines.
+type DiffSide string
+
+const (
+	DiffSideOld DiffSide = "old"
+	DiffSideNew DiffSide = "new"
+)
+
+// ReviewComment is a comment on a line of the diff, Line is the line number
+// on the given side. Comments spanning several lines start at StartLine, it
+// is 0 for single line comments. OldLine is the line number on the old side
+// of an unchanged line commented on the new side, and OldPath the path of the
+// file before a rename, both are needed to place comments on GitLab.
+type ReviewComment struct {
+	Path      string
+	OldPath   string
+	StartLine int
+	Line      int
+	OldLine   int
+	Side      DiffSide
+	Body      string
+}
+
+// PullRequestProvider is a source of pull requests to review, such as a forge
+// api or generated demo data.
+type PullRequestProvider interface {
+	// List returns the pull requests awaiting our review.
+	List(ctx context.Context) ([]PullRequestRef, error)
+	// Get fetches a single pull request with all its details.
+	Get(ctx context.Context, ref PullRequestRef) (*PullRequest, error)
+	// GetNext pops the next pull request from the review queue, ok is false
+	// when the queue is empty.
+	GetNext(ctx context.Context) (pr *PullRequest, ok bool, err error)
+	// Act performs a review action on the pull request.
+	Act(ctx context.Context, ref PullRequestRef, action PullRequestAction) error
+}
+
+const (
+	ProviderDemo    = "demo"
+	ProviderGitHub  = "github"
+	ProviderGitLab  = "gitlab"
+	ProviderGitea   = "gitea"
+	ProviderForgejo = "forgejo"
+)
+
+var Providers = []string{
+	ProviderDemo,
+	ProviderGitHub,
+	ProviderGitLab,
+	ProviderGitea,
+	ProviderForgejo,
+}
+
+type ProviderConfig struct {
+	Name  string
+	URL   string
+	Token string
+}
+
+func NewPullRequestProvider(config ProviderConfig) (PullRequestProvider, error) {
+	switch config.Name {
+	case ProviderDemo:
+		return NewDemoPullRequestProvider(), nil
+	case ProviderGitHub:
+		opts := []GitHubClientOption{
+			WithGitHubToken(config.Token),
+		}
+		if config.URL != "" {
+			opts = append(opts, WithGitHubBaseURL(config.URL))
+		}
+
+		return NewGitHubPullRequestProvider(NewGitHubClient(opts...)), nil
+	case ProviderGitLab:
+		opts := []GitLabClientOption{
+			WithGitLabToken(config.Token),
+		}
+		if config.URL != "" {
+			opts = append(opts, WithGitLabBaseURL(config.URL))
+		}
+
+		return NewGitLabMergeRequestProvider(NewGitLabClient(opts...)), nil
+	case ProviderGitea, ProviderForgejo:
+		opts := []GiteaClientOption{
+			WithGiteaToken(config.Token),
+		}
+		if config.URL != "" {
+			opts = append(opts, WithGiteaBaseURL(config.URL))
+		}
+
+		return NewGiteaPullRequestProvider(NewGiteaClient(opts...)), nil
+	default:
+		return nil, fmt.Errorf("unknown provider: %s, expected one of %v", config.Name, Providers)
+	}
+}
+
+// maxConcurrentGets bounds the pull requests fetched at once when listing, to
+// stay clear of the rate limits of the forges.
+const maxConcurrentGets = 8
+
+// GetEach fetches the pull requests concurrently, returning the pull request
+// or the error of each ref in the order of the refs. A panic while fetching is
+// returned as the error of the ref, it would take down the program from the
+// goroutine it is fetched in.
+func GetEach(
+	ctx context.Context,
+	refs []PullRequestRef,
+	get func(ctx context.Context, ref PullRequestRef) (*PullRequest, error),
+) ([]*PullRequest, []error) {
+	var (
+		wg    sync.WaitGroup
+		slots = make(chan struct{}, maxConcurrentGets)
+		prs   = make([]*PullRequest, len(refs))
+		errs  = make([]error, len(refs))
+	)
+	for i, ref := range refs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			defer func() {
+				if r := recover(); r != nil {
+					errs[i] = fmt.Errorf("failed to get %s: %v", ref, r)
+				}
+			}()
+
+			prs[i], errs[i] = get(ctx, ref)
+		}()
+	}
+	wg.Wait()
+
+	return prs, errs
+}
+
+// getAll fetches the pull requests concurrently, in the order of the refs,
+// failing with the first error.
+func getAll(
+	ctx context.Context,
+	refs []PullRequestRef,
+	get func(ctx context.Context, ref PullRequestRef) (*PullRequest, error),
+) ([]*PullRequest, error) {
+	prs, errs := GetEach(ctx, refs, get)
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return prs, nil
+}
+
+// pullRequestQueue hands out listed pull requests one at a time, listing
+// lazily on first use. Once it runs out it is listed again, picking up new
+// review requests.
+type pullRequestQueue struct {
+	mu     sync.Mutex
+	refs   []PullRequestRef
+	listed bool
+}
+
+func (q *pullRequestQueue) next(
+	ctx context.Context,
+	list func(ctx context.Context) ([]PullRequestRef, error),
+	get func(ctx context.Context, ref PullRequestRef) (*PullRequest, error),
+) (*PullRequest, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.listed {
+		refs, err := list(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		q.refs = refs
+		q.listed = true
+	}
+
+	if len(q.refs) == 0 {
+		q.listed = false
+		return nil, false, nil
+	}
+
+	ref := q.refs[0]
+	q.refs = q.refs[1:]
+
+	pr, err := get(ctx, ref)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return pr, true, nil
+}
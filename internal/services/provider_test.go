@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeProvider serves pull requests requesting squad aura, titled by the
+// number of times they were fetched, and tracks how many are fetched at once.
+type fakeProvider struct {
+	mu          sync.Mutex
+	refs        []PullRequestRef
+	gets        map[PullRequestRef]int
+	inFlight    int
+	maxInFlight int
+	queue       pullRequestQueue
+}
+
+func newFakeProvider(n int) *fakeProvider {
+	refs := make([]PullRequestRef, 0, n)
+	for i := range n {
+		refs = append(refs, PullRequestRef{Repo: "lunarway/dr", Number: i + 1})
+	}
+
+	return &fakeProvider{refs: refs, gets: make(map[PullRequestRef]int)}
+}
+
+func (f *fakeProvider) List(ctx context.Context) ([]PullRequestRef, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]PullRequestRef(nil), f.refs...), nil
+}
+
+func (f *fakeProvider) Get(ctx context.Context, ref PullRequestRef) (*PullRequest, error) {
+	f.mu.Lock()
+	f.gets[ref]++
+	gets := f.gets[ref]
+	f.inFlight++
+	f.maxInFlight = max(f.maxInFlight, f.inFlight)
+	f.mu.Unlock()
+
+	time.Sleep(time.Millisecond)
+
+	f.mu.Lock()
+	f.inFlight--
+	f.mu.Unlock()
+
+	return &PullRequest{
+		Ref:                ref,
+		Title:              fmt.Sprintf("get %d", gets),
+		CreatedAt:          time.Now(),
+		RequestedReviewers: []string{"@lunarway/squad-aura"},
+	}, nil
+}
+
+func (f *fakeProvider) GetNext(ctx context.Context) (*PullRequest, bool, error) {
+	return f.queue.next(ctx, f.List, f.Get)
+}
+
+func (f *fakeProvider) Act(ctx context.Context, ref PullRequestRef, action PullRequestAction) error {
+	return nil
+}
+
+var _ PullRequestProvider = &fakeProvider{}
+
+func TestGetAll(t *testing.T) {
+	provider := newFakeProvider(50)
+
+	prs, err := getAll(context.Background(), provider.refs, provider.Get)
+	if err != nil {
+		t.Fatalf("getAll() error = %v", err)
+	}
+
+	for i, pr := range prs {
+		if pr.Ref != provider.refs[i] {
+			t.Errorf("pull request %d = %s, want %s", i, pr.Ref, provider.refs[i])
+		}
+	}
+	if provider.maxInFlight > maxConcurrentGets || provider.maxInFlight < 2 {
+		t.Errorf("fetched %d at once, want between 2 and %d", provider.maxInFlight, maxConcurrentGets)
+	}
+}
+
+func TestGetAllError(t *testing.T) {
+	provider := newFakeProvider(10)
+	errBroken := errors.New("broken")
+
+	_, err := getAll(context.Background(), provider.refs, func(ctx context.Context, ref PullRequestRef) (*PullRequest, error) {
+		if ref.Number == 5 {
+			return nil, errBroken
+		}
+		return provider.Get(ctx, ref)
+	})
+	if !errors.Is(err, errBroken) {
+		t.Errorf("getAll() error = %v, want %v", err, errBroken)
+	}
+}
+
+func TestGetEach(t *testing.T) {
+	provider := newFakeProvider(10)
+	errBroken := errors.New("broken")
+
+	prs, errs := GetEach(context.Background(), provider.refs, func(ctx context.Context, ref PullRequestRef) (*PullRequest, error) {
+		if ref.Number%5 == 0 {
+			return nil, errBroken
+		}
+		return provider.Get(ctx, ref)
+	})
+
+	for i, ref := range provider.refs {
+		if ref.Number%5 == 0 {
+			if prs[i] != nil || !errors.Is(errs[i], errBroken) {
+				t.Errorf("GetEach()[%d] = %v, %v, want %v", i, prs[i], errs[i], errBroken)
+			}
+			continue
+		}
+		if errs[i] != nil || prs[i] == nil || prs[i].Ref != ref {
+			t.Errorf("GetEach()[%d] = %v, %v, want %s", i, prs[i], errs[i], ref)
+		}
+	}
+}
+
+func TestPullRequestQueueListsAgainOnceEmpty(t *testing.T) {
+	provider := newFakeProvider(1)
+	ctx := context.Background()
+
+	if _, ok, err := provider.GetNext(ctx); !ok || err != nil {
+		t.Fatalf("GetNext() = %v, %v, want the pull request", ok, err)
+	}
+	if _, ok, err := provider.GetNext(ctx); ok || err != nil {
+		t.Fatalf("GetNext() = %v, %v, want the queue to be empty", ok, err)
+	}
+
+	provider.mu.Lock()
+	provider.refs = append(provider.refs, PullRequestRef{Repo: "lunarway/dr", Number: 2})
+	provider.mu.Unlock()
+
+	for _, want := range []int{1, 2} {
+		pr, ok, err := provider.GetNext(ctx)
+		if !ok || err != nil {
+			t.Fatalf("GetNext() = %v, %v, want the listed pull requests again", ok, err)
+		}
+		if pr.Ref.Number != want {
+			t.Errorf("GetNext() = %s, want #%d", pr.Ref, want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"fmt"
+	"shuttle-extensions-template/internal/diff"
+	"time"
+)
+
+// PullRequestRef identifies a single pull request on a forge. Repo is the full
+// path of the repository, such as lunarway/dr.
+type PullRequestRef struct {
+	Repo   string
+	Number int
+}
+
+func (r PullRequestRef) String() string {
+	return fmt.Sprintf("%s#%d", r.Repo, r.Number)
+}
+
+type PullRequest struct {
+	Ref         PullRequestRef
+	URL         string
+	Title       string
+	Description string
+	Author      string
+	Labels      []string
+	Draft       bool
+	// BaseRef is the branch the pull request merges into, HeadRef the branch
+	// with the changes and HeadSHA the commit it currently points to.
+	BaseRef string
+	HeadRef string
+	HeadSHA string
+	// MergeState is whether the forge considers the pull request ready to
+	// merge.
+	MergeState MergeState
+	// ReviewState summarizes the latest review of each reviewer.
+	ReviewState ReviewState
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	// RequestedReviewers are users, and teams as @org/team, whose review is
+	// requested.
+	RequestedReviewers []string
+	Comments           []Comment
+	Checks             []Check
+	// Files is the diff parsed into files, it is nil when the diff couldn't
+	// be parsed.
+	Files []*diff.File
+	// Diff is the full unified diff.
+	Diff string
+	// Rank is how urgently the pull request needs a review, it is only set
+	// when listed through a RankedPullRequestProvider.
+	Rank *Rank
+}
+
+type MergeState string
+
+const (
+	// MergeStateUnknown is used while the forge is still computing whether
+	// the pull request can be merged.
+	MergeStateUnknown     MergeState = "unknown"
+	MergeStateClean       MergeState = "clean"
+	MergeStateConflicting MergeState = "conflicting"
+	// MergeStateBlocked is used when required checks, approvals or other
+	// branch rules aren't satisfied.
+	MergeStateBlocked MergeState = "blocked"
+	// MergeStateBehind is used when the branch must be updated with the base
+	// before merging.
+	MergeStateBehind MergeState = "behind"
+	// MergeStateUnstable is used when the pull request can be merged but
+	// checks which aren't required are failing.
+	MergeStateUnstable MergeState = "unstable"
+)
+
+type ReviewState string
+
+const (
+	// ReviewStatePending is used until a reviewer approves or requests
+	// changes.
+	ReviewStatePending          ReviewState = "pending"
+	ReviewStateApproved         ReviewState = "approved"
+	ReviewStateChangesRequested ReviewState = "changes_requested"
+)
+
+// summarizeReviews returns the review state given the latest verdict of each
+// reviewer, a single request for changes outweighs any approvals.
+func summarizeReviews(verdicts map[string]ReviewState) ReviewState {
+	state := ReviewStatePending
+	for _, verdict := range verdicts {
+		switch verdict {
+		case ReviewStateChangesRequested:
+			return ReviewStateChangesRequested
+		case ReviewStateApproved:
+			state = ReviewStateApproved
+		}
+	}
+
+	return state
+}
+
+// Size is the number of lines added and removed across all files.
+func (pr *PullRequest) Size() (additions, deletions int) {
+	for _, file := range pr.Files {
+		additions += file.Additions()
+		deletions += file.Deletions()
+	}
+
+	return additions, deletions
+}
+
+type Comment struct {
+	ID        string
+	Author    string
+	Body      string
+	CreatedAt time.Time
+	URL       string
+	// ThreadID groups a comment with its replies, it is empty for comments
+	// on the pull request itself which aren't part of a thread.
+	ThreadID string
+	// Path, Side and Line place a review comment on a line of the diff, they
+	// are empty for comments on the pull request itself.
+	Path string
+	Side DiffSide
+	Line int
+}
+
+type CheckStatus string
+
+const (
+	CheckStatusQueued     CheckStatus = "queued"
+	CheckStatusInProgress CheckStatus = "in_progress"
+	CheckStatusCompleted  CheckStatus = "completed"
+)
+
+type CheckConclusion string
+
+const (
+	CheckConclusionSuccess        CheckConclusion = "success"
+	CheckConclusionFailure        CheckConclusion = "failure"
+	CheckConclusionNeutral        CheckConclusion = "neutral"
+	CheckConclusionCancelled      CheckConclusion = "cancelled"
+	CheckConclusionSkipped        CheckConclusion = "skipped"
+	CheckConclusionTimedOut       CheckConclusion = "timed_out"
+	CheckConclusionActionRequired CheckConclusion = "action_required"
+)
+
+type Check struct {
+	Name   string
+	Status CheckStatus
+	// Conclusion is only set once the check is completed.
+	Conclusion CheckConclusion
+	URL        string
+}
+
+func (c Check) Failed() bool {
+	switch c.Conclusion {
+	case CheckConclusionFailure, CheckConclusionTimedOut, CheckConclusionCancelled, CheckConclusionActionRequired:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c Check) Pending() bool {
+	return c.Status != CheckStatusCompleted
+}
+
+// parseFiles parses the diff of a pull request into files, a diff which
+// can't be parsed leaves the pull request without files rather than failing
+// to fetch it.
+func parseFiles(unified string) []*diff.File {
+	files, err := diff.Parse(unified)
+	if err != nil {
+		return nil
+	}
+
+	return files
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query filters pull requests by qualifiers such as
+// "repo:foo author:bar label:deps is:draft stale:3d", other words, such as
+// "fix:", are matched against the title. Every part must match, and a part
+// prefixed with - must not.
+type Query struct {
+	terms []queryTerm
+}
+
+type queryTerm struct {
+	qualifier string
+	value     string
+	negated   bool
+	// age is the parsed value of the age and stale qualifiers.
+	age time.Duration
+}
+
+var (
+	queryQualifiers = []string{"repo", "author", "label", "reviewer", "title", "is", "age", "stale"}
+	queryStates     = []string{"draft", "approved", "changes-requested", "pending", "failing"}
+)
+
+func ParseQuery(query string) (Query, error) {
+	terms := make([]queryTerm, 0)
+	for _, field := range strings.Fields(query) {
+		term := queryTerm{}
+		if rest, ok := strings.CutPrefix(field, "-"); ok && rest != "" {
+			term.negated = true
+			field = rest
+		}
+
+		qualifier, value, ok := strings.Cut(field, ":")
+		if !ok || !slices.Contains(queryQualifiers, strings.ToLower(qualifier)) {
+			qualifier, value = "title", field
+		}
+		term.qualifier = strings.ToLower(qualifier)
+		term.value = strings.ToLower(value)
+
+		if term.value == "" {
+			return Query{}, fmt.Errorf("%q is missing a value", qualifier+":")
+		}
+
+		switch term.qualifier {
+		case "repo", "author", "label", "reviewer", "title":
+		case "is":
+			if !slices.Contains(queryStates, term.value) {
+				return Query{}, fmt.Errorf("unknown state %q, expected one of %s", value, strings.Join(queryStates, ", "))
+			}
+		case "age", "stale":
+			age, err := parseAge(term.value)
+			if err != nil {
+				return Query{}, fmt.Errorf("%s: %w", qualifier, err)
+			}
+			term.age = age
+		}
+
+		terms = append(terms, term)
+	}
+
+	return Query{terms: terms}, nil
+}
+
+// parseAge parses ages such as 30m, 12h, 3d or 2w.
+func parseAge(value string) (time.Duration, error) {
+	units := map[byte]time.Duration{
+		'm': time.Minute,
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+	}
+
+	unit, ok := units[value[len(value)-1]]
+	if !ok {
+		return 0, fmt.Errorf("invalid age %q, expected a number followed by m, h, d or w", value)
+	}
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid age %q, expected a number followed by m, h, d or w", value)
+	}
+
+	return time.Duration(n) * unit, nil
+}
+
+func (q Query) Matches(pr *PullRequest) bool {
+	for _, term := range q.terms {
+		if term.matches(pr) == term.negated {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (t queryTerm) matches(pr *PullRequest) bool {
+	switch t.qualifier {
+	case "repo":
+		return strings.Contains(strings.ToLower(pr.Ref.Repo), t.value)
+	case "author":
+		return strings.Contains(strings.ToLower(pr.Author), t.value)
+	case "label":
+		for _, label := range pr.Labels {
+			if strings.ToLower(label) == t.value {
+				return true
+			}
+		}
+
+		return false
+	case "reviewer":
+		for _, reviewer := range pr.RequestedReviewers {
+			if normalizeSquad(reviewer) == strings.TrimPrefix(t.value, "@") {
+				return true
+			}
+		}
+
+		return false
+	case "is":
+		switch t.value {
+		case "draft":
+			return pr.Draft
+		case "approved":
+			return pr.ReviewState == ReviewStateApproved
+		case "changes-requested":
+			return pr.ReviewState == ReviewStateChangesRequested
+		case "pending":
+			return pr.ReviewState == ReviewStatePending
+		case "failing":
+			return slices.ContainsFunc(pr.Checks, Check.Failed)
+		}
+
+		return false
+	case "age":
+		return time.Since(pr.CreatedAt) >= t.age
+	case "stale":
+		return time.Since(pr.UpdatedAt) >= t.age
+	default:
+		return strings.Contains(strings.ToLower(pr.Title), t.value)
+	}
+}
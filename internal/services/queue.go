@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Queue is a named review query, such as the dependency bumps owned by a
+// squad. Queries use the syntax of ParseQuery.
+type Queue struct {
+	Name   string   `yaml:"name"`
+	Query  string   `yaml:"query"`
+	Squads []string `yaml:"squads"`
+}
+
+// Config is the config file, weights left out of it keep their default.
+type Config struct {
+	Queues  []Queue        `yaml:"queues"`
+	Ranking RankingWeights `yaml:"ranking"`
+}
+
+// LoadConfig reads the config file, a missing file leaves everything at its
+// default.
+func LoadConfig(path string) (Config, error) {
+	config := Config{Ranking: DefaultRankingWeights()}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return Config{}, err
+	}
+
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return Config{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	names := make(map[string]bool, len(config.Queues))
+	for _, queue := range config.Queues {
+		switch {
+		case queue.Name == "":
+			return Config{}, fmt.Errorf("%s: every queue needs a name", path)
+		case names[queue.Name]:
+			return Config{}, fmt.Errorf("%s: queue %s is defined twice", path, queue.Name)
+		}
+		names[queue.Name] = true
+
+		if _, err := ParseQuery(queue.Query); err != nil {
+			return Config{}, fmt.Errorf("%s: queue %s: %w", path, queue.Name, err)
+		}
+	}
+
+	return config, nil
+}
+
+// Provider restricts the provider to the pull requests in the queue.
+func (q Queue) Provider(provider PullRequestProvider) (PullRequestProvider, error) {
+	query, err := ParseQuery(q.Query)
+	if err != nil {
+		return nil, fmt.Errorf("queue %s: %w", q.Name, err)
+	}
+
+	if len(q.Squads) > 0 {
+		provider = NewSquadPullRequestProvider(provider, q.Squads)
+	}
+
+	return NewQueryPullRequestProvider(provider, query), nil
+}
+
+// QueryPullRequestProvider restricts a provider to the pull requests matching
+// a query.
+type QueryPullRequestProvider struct {
+	provider PullRequestProvider
+	query    Query
+
+	// mu guards the pull requests fetched when listing, they are reused by
+	// Get until the next time the queue is listed or the pull request is
+	// acted on.
+	mu    sync.Mutex
+	prs   map[PullRequestRef]*PullRequest
+	queue pullRequestQueue
+}
+
+func NewQueryPullRequestProvider(provider PullRequestProvider, query Query) *QueryPullRequestProvider {
+	return &QueryPullRequestProvider{
+		provider: provider,
+		query:    query,
+		prs:      make(map[PullRequestRef]*PullRequest),
+	}
+}
+
+func (q *QueryPullRequestProvider) List(ctx context.Context) ([]PullRequestRef, error) {
+	refs, err := q.provider.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prs, err := getAll(ctx, refs, q.provider.Get)
+	if err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	q.prs = make(map[PullRequestRef]*PullRequest, len(prs))
+	for i, pr := range prs {
+		q.prs[refs[i]] = pr
+	}
+	q.mu.Unlock()
+
+	filtered := make([]PullRequestRef, 0, len(refs))
+	for i, pr := range prs {
+		if q.query.Matches(pr) {
+			filtered = append(filtered, refs[i])
+		}
+	}
+
+	return filtered, nil
+}
+
+func (q *QueryPullRequestProvider) Get(ctx context.Context, ref PullRequestRef) (*PullRequest, error) {
+	q.mu.Lock()
+	pr, ok := q.prs[ref]
+	q.mu.Unlock()
+	if ok {
+		return pr, nil
+	}
+
+	return q.provider.Get(ctx, ref)
+}
+
+func (q *QueryPullRequestProvider) GetNext(ctx context.Context) (*PullRequest, bool, error) {
+	return q.queue.next(ctx, q.List, q.Get)
+}
+
+func (q *QueryPullRequestProvider) Act(ctx context.Context, ref PullRequestRef, action PullRequestAction) error {
+	if err := q.provider.Act(ctx, ref, action); err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	delete(q.prs, ref)
+	q.mu.Unlock()
+
+	return nil
+}
+
+var _ PullRequestProvider = &QueryPullRequestProvider{}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		// wantErr is part of the error expected, empty when loading succeeds.
+		wantErr string
+		check   func(t *testing.T, config Config)
+	}{
+		{
+			name: "queues and weights",
+			content: `
+queues:
+  - name: deps
+    query: label:dependencies -is:draft
+    squads: ["@lunarway/squad-nasa"]
+  - name: stale
+    query: stale:3d
+ranking:
+  age: 2
+  labels:
+    urgent: 10
+`,
+			check: func(t *testing.T, config Config) {
+				want := []Queue{
+					{Name: "deps", Query: "label:dependencies -is:draft", Squads: []string{"@lunarway/squad-nasa"}},
+					{Name: "stale", Query: "stale:3d"},
+				}
+				if fmt.Sprint(config.Queues) != fmt.Sprint(want) {
+					t.Errorf("Queues = %v, want %v", config.Queues, want)
+				}
+				if config.Ranking.Age != 2 {
+					t.Errorf("Ranking.Age = %v, want 2", config.Ranking.Age)
+				}
+				if want := DefaultRankingWeights().Requested; config.Ranking.Requested != want {
+					t.Errorf("Ranking.Requested = %v, want the default %v", config.Ranking.Requested, want)
+				}
+				if config.Ranking.Labels["urgent"] != 10 {
+					t.Errorf("Ranking.Labels = %v, want urgent to weigh 10", config.Ranking.Labels)
+				}
+			},
+		},
+		{
+			name:    "invalid yaml",
+			content: "queues: [",
+			wantErr: "failed to parse",
+		},
+		{
+			name:    "missing name",
+			content: "queues:\n  - query: is:draft\n",
+			wantErr: "every queue needs a name",
+		},
+		{
+			name:    "duplicate name",
+			content: "queues:\n  - name: a\n  - name: a\n",
+			wantErr: "queue a is defined twice",
+		},
+		{
+			name:    "invalid query",
+			content: "queues:\n  - name: a\n    query: is:merged\n",
+			wantErr: `queue a: unknown state "merged"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(test.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := LoadConfig(path)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("LoadConfig() error = %v, want it to contain %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			test.check(t, config)
+		})
+	}
+}
+
+func TestLoadConfigMissing(t *testing.T) {
+	config, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if len(config.Queues) != 0 {
+		t.Errorf("Queues = %v, want none", config.Queues)
+	}
+	if fmt.Sprint(config.Ranking) != fmt.Sprint(DefaultRankingWeights()) {
+		t.Errorf("Ranking = %v, want the defaults", config.Ranking)
+	}
+}
+
+// demoRefs returns the refs of the demo pull requests matching the filter.
+func demoRefs(t *testing.T, demo *DemoPullRequestProvider, filter func(pr *PullRequest) bool) []PullRequestRef {
+	t.Helper()
+
+	refs := make([]PullRequestRef, 0)
+	for i := range demo.prs {
+		if filter(&demo.prs[i]) {
+			refs = append(refs, demo.prs[i].Ref)
+		}
+	}
+
+	return refs
+}
+
+func TestQueueProvider(t *testing.T) {
+	tests := []struct {
+		name  string
+		queue Queue
+		want  func(pr *PullRequest) bool
+	}{
+		{
+			name:  "query",
+			queue: Queue{Name: "deps", Query: "label:dependencies"},
+			want: func(pr *PullRequest) bool {
+				return slices.Contains(pr.Labels, "dependencies")
+			},
+		},
+		{
+			name:  "negated query",
+			queue: Queue{Name: "ready", Query: "-is:draft -is:failing"},
+			want: func(pr *PullRequest) bool {
+				return !pr.Draft && !slices.ContainsFunc(pr.Checks, Check.Failed)
+			},
+		},
+		{
+			name:  "query and squads",
+			queue: Queue{Name: "nasa deps", Query: "label:dependencies", Squads: []string{"@lunarway/squad-nasa"}},
+			want: func(pr *PullRequest) bool {
+				return slices.Contains(pr.Labels, "dependencies") && slices.Contains(pr.RequestedReviewers, "@lunarway/squad-nasa")
+			},
+		},
+		{
+			name:  "empty query",
+			queue: Queue{Name: "all"},
+			want:  func(pr *PullRequest) bool { return true },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			demo := NewDemoPullRequestProvider()
+			provider, err := test.queue.Provider(demo)
+			if err != nil {
+				t.Fatalf("Provider() error = %v", err)
+			}
+
+			refs, err := provider.List(context.Background())
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+
+			want := demoRefs(t, demo, test.want)
+			if len(want) == 0 || len(want) == len(demo.prs) && test.queue.Query != "" {
+				t.Fatalf("the demo data doesn't exercise the queue, %d of %d pull requests match", len(want), len(demo.prs))
+			}
+			if fmt.Sprint(refs) != fmt.Sprint(want) {
+				t.Errorf("List() = %v, want %v", refs, want)
+			}
+
+			pr, ok, err := provider.GetNext(context.Background())
+			if err != nil || !ok {
+				t.Fatalf("GetNext() = %v, %v", ok, err)
+			}
+			if pr.Ref != want[0] {
+				t.Errorf("GetNext() = %s, want %s", pr.Ref, want[0])
+			}
+		})
+	}
+}
+
+func TestQueueProviderInvalidQuery(t *testing.T) {
+	_, err := Queue{Name: "broken", Query: "is:merged"}.Provider(NewDemoPullRequestProvider())
+	if err == nil || !strings.Contains(err.Error(), "queue broken") {
+		t.Errorf("Provider() error = %v, want it to name the queue", err)
+	}
+}
+
+func TestQueryMatches(t *testing.T) {
+	demo := NewDemoPullRequestProvider()
+	pr, err := demo.Get(context.Background(), PullRequestRef{Repo: "lunarway/demo", Number: 1})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{query: "", want: true},
+		{query: "repo:lunarway/demo", want: true},
+		{query: "repo:other", want: false},
+		{query: "author:dependabot", want: true},
+		{query: "-author:dependabot", want: false},
+		{query: "label:Dependencies", want: true},
+		{query: "label:bug", want: false},
+		{query: "reviewer:@lunarway/squad-nasa", want: true},
+		{query: "reviewer:lunarway/squad-nasa", want: true},
+		{query: "reviewer:@lunarway/squad-aura", want: false},
+		{query: "is:approved", want: true},
+		{query: "is:pending", want: false},
+		{query: "is:draft", want: false},
+		{query: "age:6h", want: true},
+		{query: "age:1d", want: false},
+		{query: "stale:1w", want: false},
+		{query: "some pr", want: true},
+		{query: "other", want: false},
+		{query: "label:dependencies -is:draft age:1h", want: true},
+		{query: "label:dependencies is:draft", want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			query, err := ParseQuery(test.query)
+			if err != nil {
+				t.Fatalf("ParseQuery() error = %v", err)
+			}
+			if got := query.Matches(pr); got != test.want {
+				t.Errorf("Matches() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestQueryMatchesConventionalCommitTitles(t *testing.T) {
+	pr := &PullRequest{Title: "fix(deps): bump regexp2", Labels: []string{"dependencies"}}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{query: "fix(deps):", want: true},
+		{query: "FIX(deps): bump", want: true},
+		{query: "feat:", want: false},
+		{query: "-feat: label:dependencies", want: true},
+		{query: "-fix(deps):", want: false},
+		{query: "title:fix(deps):", want: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			query, err := ParseQuery(test.query)
+			if err != nil {
+				t.Fatalf("ParseQuery() error = %v", err)
+			}
+			if got := query.Matches(pr); got != test.want {
+				t.Errorf("Matches() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "label:", want: `"label:" is missing a value`},
+		{query: "is:merged", want: `unknown state "merged"`},
+		{query: "age:3y", want: `invalid age "3y"`},
+		{query: "stale:d", want: `invalid age "d"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			_, err := ParseQuery(test.query)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("ParseQuery() error = %v, want it to contain %q", err, test.want)
+			}
+		})
+	}
+}
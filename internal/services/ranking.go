@@ -0,0 +1,248 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Ranker scores how urgently a pull request needs a review, higher scores
+// are reviewed first.
+type Ranker interface {
+	Rank(pr *PullRequest) Rank
+}
+
+// ReviewersReader is implemented by providers able to tell who their review
+// requests are made to.
+type ReviewersReader interface {
+	// GetReviewers returns the authenticated user and the teams they are in,
+	// teams as @org/team.
+	GetReviewers(ctx context.Context) ([]string, error)
+}
+
+// Rank is the score of a pull request along with the reasons adding up to
+// it, largest first.
+type Rank struct {
+	Score   float64
+	Reasons []RankReason
+}
+
+type RankReason struct {
+	Reason string
+	Score  float64
+}
+
+func (r Rank) String() string {
+	reasons := make([]string, 0, len(r.Reasons))
+	for _, reason := range r.Reasons {
+		reasons = append(reasons, fmt.Sprintf("%s %+.1f", reason.Reason, reason.Score))
+	}
+	if len(reasons) == 0 {
+		return fmt.Sprintf("priority %.1f", r.Score)
+	}
+
+	return fmt.Sprintf("priority %.1f: %s", r.Score, strings.Join(reasons, ", "))
+}
+
+// RankingWeights are the weights of each factor when ranking pull requests,
+// negative weights push pull requests back in the queue.
+type RankingWeights struct {
+	// Age is added for each day since the pull request was opened.
+	Age float64 `yaml:"age"`
+	// Size is added for each 100 lines added or removed.
+	Size float64 `yaml:"size"`
+	// Reviewers is added for each requested reviewer.
+	Reviewers     float64 `yaml:"reviewers"`
+	FailingChecks float64 `yaml:"failing_checks"`
+	// Requested is added when one of our reviewers is requested explicitly,
+	// rather than the pull request only touching files we own.
+	Requested float64 `yaml:"requested"`
+	// Labels are added for each label on the pull request.
+	Labels map[string]float64 `yaml:"labels"`
+}
+
+// DefaultRankingWeights serves old, small pull requests requesting us first,
+// and holds back the ones with failing checks.
+func DefaultRankingWeights() RankingWeights {
+	return RankingWeights{
+		Age:           1,
+		Size:          -0.5,
+		Reviewers:     -0.5,
+		FailingChecks: -3,
+		Requested:     5,
+		Labels: map[string]float64{
+			"security": 5,
+			"hotfix":   5,
+		},
+	}
+}
+
+// WeightedRanker ranks pull requests by the sum of the weighted factors,
+// reviewers are the users and teams who count as us.
+type WeightedRanker struct {
+	weights   RankingWeights
+	reviewers []string
+}
+
+func NewWeightedRanker(weights RankingWeights, reviewers []string) *WeightedRanker {
+	normalized := make([]string, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		normalized = append(normalized, normalizeSquad(reviewer))
+	}
+
+	labels := make(map[string]float64, len(weights.Labels))
+	for label, weight := range weights.Labels {
+		labels[strings.ToLower(label)] = weight
+	}
+	weights.Labels = labels
+
+	return &WeightedRanker{
+		weights:   weights,
+		reviewers: normalized,
+	}
+}
+
+func (w *WeightedRanker) Rank(pr *PullRequest) Rank {
+	var rank Rank
+	add := func(score float64, reason string, args ...any) {
+		if score == 0 {
+			return
+		}
+		rank.Score += score
+		rank.Reasons = append(rank.Reasons, RankReason{Reason: fmt.Sprintf(reason, args...), Score: score})
+	}
+
+	for _, reviewer := range pr.RequestedReviewers {
+		if slices.Contains(w.reviewers, normalizeSquad(reviewer)) {
+			add(w.weights.Requested, "requested from %s", reviewer)
+			break
+		}
+	}
+	for _, label := range pr.Labels {
+		add(w.weights.Labels[strings.ToLower(label)], "labelled %s", label)
+	}
+	if slices.ContainsFunc(pr.Checks, Check.Failed) {
+		add(w.weights.FailingChecks, "failing checks")
+	}
+
+	days := time.Since(pr.CreatedAt).Hours() / 24
+	add(w.weights.Age*days, "open %dd", int(days))
+
+	additions, deletions := pr.Size()
+	add(w.weights.Size*float64(additions+deletions)/100, "%d lines changed", additions+deletions)
+
+	if len(pr.RequestedReviewers) > 0 {
+		add(w.weights.Reviewers*float64(len(pr.RequestedReviewers)), "%d requested reviewers", len(pr.RequestedReviewers))
+	}
+
+	sort.SliceStable(rank.Reasons, func(i, j int) bool {
+		return math.Abs(rank.Reasons[i].Score) > math.Abs(rank.Reasons[j].Score)
+	})
+
+	return rank
+}
+
+var _ Ranker = &WeightedRanker{}
+
+// RankedPullRequestProvider lists the pull requests of a provider most
+// urgent first, so GetNext always serves the most urgent one. The pull
+// requests it returns carry their rank.
+type RankedPullRequestProvider struct {
+	provider PullRequestProvider
+	ranker   Ranker
+
+	// mu guards the ranked pull requests, they are reused by Get until the
+	// next time the queue is listed or the pull request is acted on.
+	mu    sync.Mutex
+	prs   map[PullRequestRef]*PullRequest
+	queue pullRequestQueue
+}
+
+func NewRankedPullRequestProvider(provider PullRequestProvider, ranker Ranker) *RankedPullRequestProvider {
+	return &RankedPullRequestProvider{
+		provider: provider,
+		ranker:   ranker,
+		prs:      make(map[PullRequestRef]*PullRequest),
+	}
+}
+
+func (r *RankedPullRequestProvider) List(ctx context.Context) ([]PullRequestRef, error) {
+	refs, err := r.provider.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.prs = make(map[PullRequestRef]*PullRequest, len(refs))
+	r.mu.Unlock()
+
+	prs, err := getAll(ctx, refs, r.rank)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make(map[PullRequestRef]float64, len(refs))
+	for i, pr := range prs {
+		scores[refs[i]] = pr.Rank.Score
+	}
+
+	ranked := slices.Clone(refs)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i]] > scores[ranked[j]]
+	})
+
+	return ranked, nil
+}
+
+// rank fetches the pull request and ranks a copy of it, leaving the one
+// cached by the provider untouched.
+func (r *RankedPullRequestProvider) rank(ctx context.Context, ref PullRequestRef) (*PullRequest, error) {
+	pr, err := r.provider.Get(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := *pr
+	rank := r.ranker.Rank(pr)
+	ranked.Rank = &rank
+
+	r.mu.Lock()
+	r.prs[ref] = &ranked
+	r.mu.Unlock()
+
+	return &ranked, nil
+}
+
+func (r *RankedPullRequestProvider) Get(ctx context.Context, ref PullRequestRef) (*PullRequest, error) {
+	r.mu.Lock()
+	pr, ok := r.prs[ref]
+	r.mu.Unlock()
+	if ok {
+		return pr, nil
+	}
+
+	return r.rank(ctx, ref)
+}
+
+func (r *RankedPullRequestProvider) GetNext(ctx context.Context) (*PullRequest, bool, error) {
+	return r.queue.next(ctx, r.List, r.Get)
+}
+
+func (r *RankedPullRequestProvider) Act(ctx context.Context, ref PullRequestRef, action PullRequestAction) error {
+	if err := r.provider.Act(ctx, ref, action); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	delete(r.prs, ref)
+	r.mu.Unlock()
+
+	return nil
+}
+
+var _ PullRequestProvider = &RankedPullRequestProvider{}
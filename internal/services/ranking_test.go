@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCachedPullRequestsAreRefreshed(t *testing.T) {
+	tests := []struct {
+		name string
+		wrap func(provider PullRequestProvider) PullRequestProvider
+	}{
+		{
+			name: "ranked",
+			wrap: func(provider PullRequestProvider) PullRequestProvider { return provider },
+		},
+		{
+			name: "query",
+			wrap: func(provider PullRequestProvider) PullRequestProvider {
+				return NewQueryPullRequestProvider(provider, Query{})
+			},
+		},
+		{
+			name: "squad",
+			wrap: func(provider PullRequestProvider) PullRequestProvider {
+				return NewSquadPullRequestProvider(provider, []string{"@lunarway/squad-aura"})
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			base := newFakeProvider(20)
+			provider := NewRankedPullRequestProvider(test.wrap(base), NewWeightedRanker(DefaultRankingWeights(), nil))
+			ref := base.refs[0]
+
+			title := func() string {
+				pr, err := provider.Get(ctx, ref)
+				if err != nil {
+					t.Fatalf("Get() error = %v", err)
+				}
+				return pr.Title
+			}
+
+			refs, err := provider.List(ctx)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(refs) != len(base.refs) {
+				t.Fatalf("List() returned %d pull requests, want %d", len(refs), len(base.refs))
+			}
+			if got := title(); got != "get 1" {
+				t.Errorf("after listing, Get() = %q, want the listed pull request", got)
+			}
+			if base.maxInFlight < 2 || base.maxInFlight > maxConcurrentGets {
+				t.Errorf("fetched %d at once, want between 2 and %d", base.maxInFlight, maxConcurrentGets)
+			}
+
+			if _, err := provider.List(ctx); err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if got := title(); got != "get 2" {
+				t.Errorf("after listing again, Get() = %q, want it fetched again", got)
+			}
+
+			if err := provider.Act(ctx, ref, PullRequestAction{Kind: PullRequestActionApprove}); err != nil {
+				t.Fatalf("Act() error = %v", err)
+			}
+			if got := title(); got != "get 3" {
+				t.Errorf("after acting, Get() = %q, want it fetched again", got)
+			}
+		})
+	}
+}
+
+func TestWeightedRankerRequested(t *testing.T) {
+	demo := NewDemoPullRequestProvider()
+	reviewers, err := demo.GetReviewers(context.Background())
+	if err != nil {
+		t.Fatalf("GetReviewers() error = %v", err)
+	}
+	ranker := NewWeightedRanker(DefaultRankingWeights(), reviewers)
+
+	for _, pr := range demo.prs {
+		rank := ranker.Rank(&pr)
+		requested := strings.Contains(rank.String(), "requested from")
+		if want := pr.RequestedReviewers[0] == reviewers[0]; requested != want {
+			t.Errorf("%s requesting %v ranked %q, want requested = %v", pr.Ref, pr.RequestedReviewers, rank, want)
+		}
+	}
+}
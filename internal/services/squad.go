@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// CodeOwnersReader is implemented by providers able to read the CODEOWNERS
+// file of a repository.
+type CodeOwnersReader interface {
+	// GetCodeOwners returns the raw CODEOWNERS file of the repository, or an
+	// empty string if it has none.
+	GetCodeOwners(ctx context.Context, repo string) (string, error)
+}
+
+// SquadPullRequestProvider restricts a provider to pull requests where one
+// of the squads is a requested reviewer, or owns a touched file according to
+// CODEOWNERS.
+type SquadPullRequestProvider struct {
+	provider PullRequestProvider
+	squads   []string
+
+	// mu guards the caches, pull requests are loaded concurrently. They are
+	// dropped when listing again, and a pull request once acted on.
+	mu         sync.Mutex
+	prs        map[PullRequestRef]*PullRequest
+	codeOwners map[string]*CodeOwners
+	queue      pullRequestQueue
+}
+
+func NewSquadPullRequestProvider(provider PullRequestProvider, squads []string) *SquadPullRequestProvider {
+	normalized := make([]string, 0, len(squads))
+	for _, squad := range squads {
+		normalized = append(normalized, normalizeSquad(squad))
+	}
+
+	return &SquadPullRequestProvider{
+		provider:   provider,
+		squads:     normalized,
+		prs:        make(map[PullRequestRef]*PullRequest),
+		codeOwners: make(map[string]*CodeOwners),
+	}
+}
+
+func (s *SquadPullRequestProvider) List(ctx context.Context) ([]PullRequestRef, error) {
+	refs, err := s.provider.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.prs = make(map[PullRequestRef]*PullRequest, len(refs))
+	s.codeOwners = make(map[string]*CodeOwners)
+	s.mu.Unlock()
+
+	prs, err := getAll(ctx, refs, s.Get)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]PullRequestRef, 0, len(refs))
+	for i, pr := range prs {
+		ok, err := s.matches(ctx, pr)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			filtered = append(filtered, refs[i])
+		}
+	}
+
+	return filtered, nil
+}
+
+func (s *SquadPullRequestProvider) Get(ctx context.Context, ref PullRequestRef) (*PullRequest, error) {
+	s.mu.Lock()
+	pr, ok := s.prs[ref]
+	s.mu.Unlock()
+	if ok {
+		return pr, nil
+	}
+
+	pr, err := s.provider.Get(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.prs[ref] = pr
+	s.mu.Unlock()
+
+	return pr, nil
+}
+
+func (s *SquadPullRequestProvider) GetNext(ctx context.Context) (*PullRequest, bool, error) {
+	return s.queue.next(ctx, s.List, s.Get)
+}
+
+func (s *SquadPullRequestProvider) Act(ctx context.Context, ref PullRequestRef, action PullRequestAction) error {
+	if err := s.provider.Act(ctx, ref, action); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.prs, ref)
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *SquadPullRequestProvider) matches(ctx context.Context, pr *PullRequest) (bool, error) {
+	for _, reviewer := range pr.RequestedReviewers {
+		if s.isSquad(reviewer) {
+			return true, nil
+		}
+	}
+
+	codeOwners, err := s.getCodeOwners(ctx, pr.Ref.Repo)
+	if err != nil {
+		return false, err
+	}
+
+	for _, file := range pr.Files {
+		for _, owner := range codeOwners.Owners(file.Path()) {
+			if s.isSquad(owner) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func (s *SquadPullRequestProvider) getCodeOwners(ctx context.Context, repo string) (*CodeOwners, error) {
+	s.mu.Lock()
+	codeOwners, ok := s.codeOwners[repo]
+	s.mu.Unlock()
+	if ok {
+		return codeOwners, nil
+	}
+
+	var content string
+	if reader, ok := s.provider.(CodeOwnersReader); ok {
+		var err error
+		content, err = reader.GetCodeOwners(ctx, repo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	codeOwners = ParseCodeOwners(content)
+	s.mu.Lock()
+	s.codeOwners[repo] = codeOwners
+	s.mu.Unlock()
+
+	return codeOwners, nil
+}
+
+func (s *SquadPullRequestProvider) isSquad(owner string) bool {
+	owner = normalizeSquad(owner)
+	for _, squad := range s.squads {
+		if owner == squad {
+			return true
+		}
+	}
+
+	return false
+}
+
+func normalizeSquad(squad string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(squad), "@"))
+}
+
+var _ PullRequestProvider = &SquadPullRequestProvider{}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"shuttle-extensions-template/internal/diff"
+	"testing"
+)
+
+// codeOwnersProvider serves fixed pull requests and CODEOWNERS files.
+type codeOwnersProvider struct {
+	fakeProvider
+	prs        map[PullRequestRef]*PullRequest
+	codeOwners map[string]string
+}
+
+func (c *codeOwnersProvider) Get(ctx context.Context, ref PullRequestRef) (*PullRequest, error) {
+	pr, ok := c.prs[ref]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return pr, nil
+}
+
+func (c *codeOwnersProvider) GetCodeOwners(ctx context.Context, repo string) (string, error) {
+	return c.codeOwners[repo], nil
+}
+
+func TestSquadPullRequestProvider(t *testing.T) {
+	changing := func(paths ...string) []*diff.File {
+		files := make([]*diff.File, 0, len(paths))
+		for _, path := range paths {
+			files = append(files, &diff.File{OldPath: path, NewPath: path})
+		}
+		return files
+	}
+	prs := []*PullRequest{
+		{Title: "requested", RequestedReviewers: []string{"alice", "@LunarWay/Squad-Aura"}},
+		{Title: "owned", Files: changing("README.md", "internal/pay/card.go")},
+		{Title: "docs", Files: changing("docs/index.md")},
+		{Title: "nested docs", Files: changing("docs/x/y.md")},
+		{Title: "other squad", RequestedReviewers: []string{"@lunarway/squad-nasa"}, Files: changing("main.go")},
+		{Title: "no codeowners", Files: changing("internal/pay/card.go")},
+	}
+
+	provider := &codeOwnersProvider{
+		prs: make(map[PullRequestRef]*PullRequest),
+		codeOwners: map[string]string{
+			"lunarway/dr": "* @lunarway/squad-nasa\n/internal/pay/ @lunarway/squad-aura\ndocs/* @lunarway/squad-aura\n",
+		},
+	}
+	for i, pr := range prs {
+		pr.Ref = PullRequestRef{Repo: "lunarway/dr", Number: i + 1}
+		if pr.Title == "no codeowners" {
+			pr.Ref.Repo = "lunarway/other"
+		}
+		provider.refs = append(provider.refs, pr.Ref)
+		provider.prs[pr.Ref] = pr
+	}
+
+	refs, err := NewSquadPullRequestProvider(provider, []string{"lunarway/squad-aura"}).List(context.Background())
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	var got []string
+	for _, ref := range refs {
+		got = append(got, provider.prs[ref].Title)
+	}
+	if want := "[requested owned docs]"; fmt.Sprint(got) != want {
+		t.Errorf("List() = %v, want %s", got, want)
+	}
+}
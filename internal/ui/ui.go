@@ -2,14 +2,48 @@ package ui
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
 	"shuttle-extensions-template/internal/app"
 	"shuttle-extensions-template/internal/pages"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-func ReviewApp(ctx context.Context) error {
-	p := tea.NewProgram(app.NewApp(app.WithPage(pages.PullRequestTablePage)), tea.WithAltScreen())
+func ReviewApp(ctx context.Context, queues []pages.Queue, queue string) (err error) {
+	p := tea.NewProgram(
+		app.NewApp(
+			ctx,
+			queues,
+			app.WithPage(pages.PullRequestTablePage),
+			app.WithQueue(queue),
+		),
+		tea.WithAltScreen(),
+		tea.WithoutCatchPanics(),
+	)
+
+	// Panics in the update loop are recovered here rather than by Bubble Tea,
+	// which only prints them, so the terminal is restored and the stack kept
+	// in a crash report. Commands run in their own goroutines, the pages
+	// recover those that call providers themselves.
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		_ = p.ReleaseTerminal()
+
+		path, reportErr := writeCrashReport(r, debug.Stack())
+		if reportErr != nil {
+			err = fmt.Errorf("review app crashed: %v (failed to write crash report: %w)", r, reportErr)
+			return
+		}
+		err = fmt.Errorf("review app crashed: %v, a crash report was written to %s", r, path)
+	}()
 
 	if _, err := p.Run(); err != nil {
 		return err
@@ -17,3 +51,14 @@ func ReviewApp(ctx context.Context) error {
 
 	return nil
 }
+
+func writeCrashReport(r any, stack []byte) (string, error) {
+	path := filepath.Join(os.TempDir(), fmt.Sprintf("dr-crash-%s.log", time.Now().Format("20060102-150405")))
+
+	report := fmt.Sprintf("panic: %v\n\n%s", r, stack)
+	if err := os.WriteFile(path, []byte(report), 0o600); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}